@@ -16,8 +16,25 @@ func parseEnvs[T any]() (T, error) {
 type Service struct {
 	GracePeriod time.Duration `envconfig:"grace_period" default:"15s"`
 
-	GRPCAddress string `envconfig:"grpc_address" default:":8081"`
-	HTTPAddress string `envconfig:"http_address" default:":8082"`
+	GRPCAddress      string `envconfig:"grpc_address" default:":8081"`
+	HTTPAddress      string `envconfig:"http_address" default:":8082"`
+	AdminGRPCAddress string `envconfig:"admin_grpc_address" default:":8083"`
+
+	// AttributeSchema lists custom user attributes as key:type pairs, e.g. "department:string,floor:int"
+	AttributeSchema map[string]string `envconfig:"attribute_schema"`
+	// ActivationPolicy decides when user becomes active: verified_contact, any_contact, email or always
+	ActivationPolicy string `envconfig:"activation_policy" default:"verified_contact"`
+	// UserStorage is state to keep users as rows or event_sourced to keep them as event streams with rows as projection
+	UserStorage string `envconfig:"user_storage" default:"state"`
+	// EmailNormalization is domain to compare only domain part of emails case-insensitively or full for whole address
+	EmailNormalization string `envconfig:"email_normalization" default:"domain"`
+	// PasswordMaxFailedAttempts failed password verifications in a row lock credentials for PasswordLockoutDuration
+	PasswordMaxFailedAttempts int           `envconfig:"password_max_failed_attempts" default:"5"`
+	PasswordLockoutDuration   time.Duration `envconfig:"password_lockout_duration" default:"15m"`
+	// MFAEncryptionKey is base64 of 32 bytes AES key encrypting totp secrets, totp is unavailable when key is empty
+	MFAEncryptionKey string `envconfig:"mfa_encryption_key"`
+	// TOTPIssuer is shown by authenticator apps next to account login
+	TOTPIssuer string `envconfig:"totp_issuer" default:"userservice"`
 }
 
 type Database struct {
@@ -5,6 +5,9 @@ import (
 
 	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
 	"github.com/pkg/errors"
+
+	appservice "userservice/pkg/user/application/service"
+	inframysql "userservice/pkg/user/infrastructure/mysql"
 )
 
 func newDatabaseConnector(config Database) (mysql.Connector, error) {
@@ -17,6 +20,17 @@ func newDatabaseConnector(config Database) (mysql.Connector, error) {
 	return connector, errors.WithStack(err)
 }
 
+func newRepositoryProviderBuilder(userStorage string) (mysql.RepositoryProviderBuilder[appservice.RepositoryProvider], error) {
+	switch userStorage {
+	case "state":
+		return inframysql.NewRepositoryProvider, nil
+	case "event_sourced":
+		return inframysql.NewEventSourcedRepositoryProvider, nil
+	default:
+		return nil, errors.Errorf("unknown user storage %q", userStorage)
+	}
+}
+
 func dsn(config Database) string {
 	return fmt.Sprintf(
 		"%s:%s@(%s)/%s?charset=utf8mb4&collation=utf8mb4_unicode_ci&parseTime=true",
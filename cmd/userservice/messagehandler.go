@@ -67,15 +67,16 @@ func messageHandler(logger logging.Logger) *cli.Command {
 				ExchangeName: integrationevent.ExchangeName,
 				RoutingKeys:  []string{integrationevent.RoutingKeyPrefix + "#"},
 			}
-			amqpEventProducer := amqpConnection.Producer(
-				&amqp.ExchangeConfig{
+			amqpEventProducer := integrationevent.NewProducer(
+				appID,
+				amqp.ExchangeConfig{
 					Name:    integrationevent.ExchangeName,
 					Kind:    integrationevent.ExchangeKind,
 					Durable: true,
 				},
-				queueConfig,
-				bindConfig,
+				logger,
 			)
+			amqpConnection.AddChannel(amqpEventProducer)
 			amqpTransport := integrationevent.NewAMQPTransport(logger, workflowService)
 			amqpConnection.Consumer(
 				c.Context,
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+
+	domainservice "userservice/pkg/user/domain/service"
+)
+
+const mfaEncryptionKeyLength = 32
+
+// newSecretCipher returns nil cipher for empty key, totp is unavailable then
+func newSecretCipher(encodedKey string) (domainservice.SecretCipher, error) {
+	if encodedKey == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid mfa encryption key")
+	}
+	if len(key) != mfaEncryptionKeyLength {
+		return nil, errors.Errorf("mfa encryption key must be %d bytes long", mfaEncryptionKeyLength)
+	}
+	return domainservice.NewAESSecretCipher(key)
+}
@@ -9,11 +9,13 @@ import (
 	outboxmigrations "gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/outbox/migrations"
 	"github.com/urfave/cli/v2"
 
+	"userservice/pkg/user/domain/model"
 	"userservice/pkg/user/infrastructure/integrationevent"
 	"userservice/pkg/user/infrastructure/migrations/database"
 )
 
 type migrateConfig struct {
+	Service  Service  `envconfig:"service"`
 	Database Database `envconfig:"database" required:"true"`
 }
 
@@ -48,7 +50,11 @@ func migrateImpl(logger logging.Logger) func(c *cli.Context) error {
 		closer.AddCloser(connector)
 		connPool := mysql.NewConnectionPool(connector.TransactionalClient())
 
-		databaseMigrator, closeDatabaseMigrator, err := database.NewDatabaseMigrator(c.Context, connPool, logger)
+		emailNormalization, err := model.ParseEmailNormalization(cnf.Service.EmailNormalization)
+		if err != nil {
+			return err
+		}
+		databaseMigrator, closeDatabaseMigrator, err := database.NewDatabaseMigrator(c.Context, connPool, logger, emailNormalization)
 		if err != nil {
 			return err
 		}
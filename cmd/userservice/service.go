@@ -16,8 +16,10 @@ import (
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 
+	"userservice/api/server/useradminapi"
 	"userservice/api/server/userpublicapi"
 	appservice "userservice/pkg/user/application/service"
+	"userservice/pkg/user/domain/model"
 	"userservice/pkg/user/infrastructure/integrationevent"
 	inframysql "userservice/pkg/user/infrastructure/mysql"
 	"userservice/pkg/user/infrastructure/mysql/query"
@@ -39,6 +41,26 @@ func service(logger logging.Logger) *cli.Command {
 			if err != nil {
 				return err
 			}
+			attributeSchema, err := model.ParseAttributeSchema(cnf.Service.AttributeSchema)
+			if err != nil {
+				return err
+			}
+			repositoryProviderBuilder, err := newRepositoryProviderBuilder(cnf.Service.UserStorage)
+			if err != nil {
+				return err
+			}
+			emailNormalization, err := model.ParseEmailNormalization(cnf.Service.EmailNormalization)
+			if err != nil {
+				return err
+			}
+			passwordLockout, err := model.NewPasswordLockout(cnf.Service.PasswordMaxFailedAttempts, cnf.Service.PasswordLockoutDuration)
+			if err != nil {
+				return err
+			}
+			secretCipher, err := newSecretCipher(cnf.Service.MFAEncryptionKey)
+			if err != nil {
+				return err
+			}
 
 			closer := libio.NewMultiCloser()
 			defer func() {
@@ -52,16 +74,33 @@ func service(logger logging.Logger) *cli.Command {
 			closer.AddCloser(databaseConnector)
 			databaseConnectionPool := mysql.NewConnectionPool(databaseConnector.TransactionalClient())
 
-			libUoW := mysql.NewUnitOfWork(databaseConnectionPool, inframysql.NewRepositoryProvider)
+			libUoW := mysql.NewUnitOfWork(databaseConnectionPool, repositoryProviderBuilder)
 			libLUow := mysql.NewLockableUnitOfWork(libUoW, mysql.NewLocker(databaseConnectionPool))
 			uow := inframysql.NewUnitOfWork(libUoW)
 			luow := inframysql.NewLockableUnitOfWork(libLUow)
 			eventDispatcher := outbox.NewEventDispatcher(appID, integrationevent.TransportName, integrationevent.NewEventSerializer(), libUoW)
 
-			userPublicAPIServer := transport.NewUserInternalAPI(
-				query.NewUserQueryService(databaseConnector.TransactionalClient()),
-				appservice.NewUserService(uow, luow, eventDispatcher),
+			userQueryService := query.NewUserQueryService(databaseConnector.TransactionalClient(), attributeSchema)
+			userService := appservice.NewUserService(uow, luow, eventDispatcher, attributeSchema, emailNormalization)
+			organizationQueryService := query.NewOrganizationQueryService(databaseConnector.TransactionalClient())
+			organizationService := appservice.NewOrganizationService(luow, eventDispatcher)
+			credentialsService := appservice.NewCredentialsService(uow, luow, eventDispatcher, passwordLockout)
+			apiTokenService := appservice.NewAPITokenService(uow, luow, eventDispatcher)
+			totpService := appservice.NewTOTPService(luow, eventDispatcher, secretCipher, cnf.Service.TOTPIssuer, passwordLockout)
+			identityService := appservice.NewIdentityService(luow, eventDispatcher)
+			userPublicAPIServer := transport.NewUserPublicAPI(
+				userQueryService,
+				organizationQueryService,
+				userService,
+				organizationService,
+				credentialsService,
+				apiTokenService,
+				totpService,
+				identityService,
 			)
+			roleService := appservice.NewRoleService(luow, eventDispatcher)
+			userAdminAPIServer := transport.NewUserAdminAPI(userQueryService, userService, roleService)
+			metricsMiddleware := middlewares.NewGRPCMetricsMiddleware()
 
 			errGroup := errgroup.Group{}
 			errGroup.Go(func() error {
@@ -70,8 +109,11 @@ func service(logger logging.Logger) *cli.Command {
 					return err
 				}
 				grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
+					middlewares.NewGRPCErrorsMiddleware(),
 					middlewares.NewGRPCLoggingMiddleware(logger),
-					middlewares.NewGRPCMetricsMiddleware(),
+					middlewares.NewGRPCActorMiddleware(appservice.ActorSourcePublicAPI),
+					middlewares.NewGRPCTenantMiddleware(),
+					metricsMiddleware,
 				))
 				userpublicapi.RegisterUserPublicAPIServer(grpcServer, userPublicAPIServer)
 				graceCallback(c.Context, logger, cnf.Service.GracePeriod, func(_ context.Context) error {
@@ -80,6 +122,25 @@ func service(logger logging.Logger) *cli.Command {
 				})
 				return grpcServer.Serve(listener)
 			})
+			errGroup.Go(func() error {
+				listener, err := net.Listen("tcp", cnf.Service.AdminGRPCAddress)
+				if err != nil {
+					return err
+				}
+				grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
+					middlewares.NewGRPCErrorsMiddleware(),
+					middlewares.NewGRPCLoggingMiddleware(logger.WithField("api", "admin")),
+					middlewares.NewGRPCActorMiddleware(appservice.ActorSourceAdminAPI),
+					middlewares.NewGRPCTenantMiddleware(),
+					metricsMiddleware,
+				))
+				useradminapi.RegisterUserAdminAPIServer(grpcServer, userAdminAPIServer)
+				graceCallback(c.Context, logger, cnf.Service.GracePeriod, func(_ context.Context) error {
+					grpcServer.GracefulStop()
+					return nil
+				})
+				return grpcServer.Serve(listener)
+			})
 			errGroup.Go(func() error {
 				router := mux.NewRouter()
 				registerHealthcheck(router)
@@ -14,6 +14,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	appservice "userservice/pkg/user/application/service"
+	"userservice/pkg/user/domain/model"
 	"userservice/pkg/user/infrastructure/integrationevent"
 	inframysql "userservice/pkg/user/infrastructure/mysql"
 	"userservice/pkg/user/infrastructure/temporal"
@@ -35,6 +36,22 @@ func workflowWorker(logger logging.Logger) *cli.Command {
 			if err != nil {
 				return err
 			}
+			attributeSchema, err := model.ParseAttributeSchema(cnf.Service.AttributeSchema)
+			if err != nil {
+				return err
+			}
+			repositoryProviderBuilder, err := newRepositoryProviderBuilder(cnf.Service.UserStorage)
+			if err != nil {
+				return err
+			}
+			emailNormalization, err := model.ParseEmailNormalization(cnf.Service.EmailNormalization)
+			if err != nil {
+				return err
+			}
+			activationPolicy, err := model.ParseActivationPolicy(cnf.Service.ActivationPolicy)
+			if err != nil {
+				return err
+			}
 
 			closer := libio.NewMultiCloser()
 			defer func() {
@@ -57,7 +74,7 @@ func workflowWorker(logger logging.Logger) *cli.Command {
 				return nil
 			}))
 
-			libUoW := mysql.NewUnitOfWork(databaseConnectionPool, inframysql.NewRepositoryProvider)
+			libUoW := mysql.NewUnitOfWork(databaseConnectionPool, repositoryProviderBuilder)
 			libLUow := mysql.NewLockableUnitOfWork(libUoW, mysql.NewLocker(databaseConnectionPool))
 			uow := inframysql.NewUnitOfWork(libUoW)
 			luow := inframysql.NewLockableUnitOfWork(libLUow)
@@ -65,7 +82,7 @@ func workflowWorker(logger logging.Logger) *cli.Command {
 
 			errGroup := errgroup.Group{}
 			errGroup.Go(func() error {
-				w := worker.NewWorker(temporalClient, appservice.NewUserService(uow, luow, eventDispatcher))
+				w := worker.NewWorker(temporalClient, appservice.NewUserService(uow, luow, eventDispatcher, attributeSchema, emailNormalization), activationPolicy)
 				return w.Run(worker.InterruptChannel())
 			})
 
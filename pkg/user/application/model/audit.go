@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditRecord is a change of user, OldValue and NewValue are JSON objects of changed fields, OldValue is empty for new user
+type AuditRecord struct {
+	AuditID       int64
+	UserID        uuid.UUID
+	Actor         string
+	Source        string
+	CorrelationID string
+	OldValue      *string
+	NewValue      string
+	CreatedAt     time.Time
+}
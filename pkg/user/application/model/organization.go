@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Organization struct {
+	OrganizationID uuid.UUID
+	Name           string
+	Members        []Member
+}
+
+type Member struct {
+	UserID      uuid.UUID
+	Role        int
+	DisplayName *string
+	JoinedAt    time.Time
+}
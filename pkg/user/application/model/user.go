@@ -1,11 +1,53 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type User struct {
 	UserID   uuid.UUID
+	TenantID string
 	Status   int
-	Login    string
-	Email    *string
-	Telegram *string
+	// StatusSource is manual when status is set by admin and is not recomputed automatically
+	StatusSource int
+	// StatusExpiresAt is set for temporary status
+	StatusExpiresAt *time.Time
+	Login           string
+	DisplayName     *string
+	Locale          *string
+	Timezone        *string
+	AvatarURL       *string
+	Contacts        []Contact
+	// Attributes are values of custom attributes in canonical form of their types
+	Attributes map[string]string
+	Labels     []string
+	// NotificationPreferences are explicit choices of user, defaults are up to notification services
+	NotificationPreferences []NotificationPreference
+	Roles                   []string
+	// Memberships are organizations user belongs to
+	Memberships []Membership
+	// MergedInto is set for user deleted by merge into another user
+	MergedInto *uuid.UUID
+}
+
+type Contact struct {
+	Type       int
+	Value      string
+	Primary    bool
+	VerifiedAt *time.Time
+}
+
+type NotificationPreference struct {
+	// Channel is contact type notifications are sent over
+	Channel  int
+	Category string
+	Enabled  bool
+}
+
+type Membership struct {
+	OrganizationID uuid.UUID
+	Role           int
+	DisplayName    *string
 }
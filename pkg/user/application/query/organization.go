@@ -0,0 +1,13 @@
+package query
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	appmodel "userservice/pkg/user/application/model"
+)
+
+type OrganizationQueryService interface {
+	FindOrganization(ctx context.Context, organizationID uuid.UUID) (*appmodel.Organization, error)
+}
@@ -8,6 +8,33 @@ import (
 	appmodel "userservice/pkg/user/application/model"
 )
 
+type ListSpec struct {
+	AfterUserID *uuid.UUID
+	Limit       int
+	// Labels filters users having all of labels, labels are matched case-insensitively
+	Labels []string
+	// Attributes filters users having all of attributes, values are brought to canonical form of attribute type
+	// and keys missing in attribute schema are rejected
+	Attributes map[string]string
+	// OrganizationID filters members of organization
+	OrganizationID *uuid.UUID
+}
+
+type HistorySpec struct {
+	UserID       uuid.UUID
+	AfterAuditID *int64
+	Limit        int
+}
+
 type UserQueryService interface {
 	FindUser(ctx context.Context, userID uuid.UUID) (*appmodel.User, error)
+	// FindUserByIdentity finds user linked to account of external identity provider, deleted users are not found
+	FindUserByIdentity(ctx context.Context, issuer, subject string) (*appmodel.User, error)
+	ListUsers(ctx context.Context, spec ListSpec) ([]appmodel.User, error)
+	// CheckPermission reports whether any role of user grants permission, permissions are granted only to active users
+	CheckPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error)
+	// GetUserHistory returns changes of user in order they were made, history is kept after user deletion
+	GetUserHistory(ctx context.Context, spec HistorySpec) ([]appmodel.AuditRecord, error)
+	// ListAPITokens returns api tokens of user including expired ones
+	ListAPITokens(ctx context.Context, userID uuid.UUID) ([]appmodel.APIToken, error)
 }
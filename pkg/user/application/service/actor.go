@@ -0,0 +1,29 @@
+package service
+
+import "context"
+
+// Actor sources recorded in user audit
+const (
+	ActorSourcePublicAPI = "public_api"
+	ActorSourceAdminAPI  = "admin_api"
+	ActorSourceWorkflow  = "workflow"
+)
+
+// Actor is the initiator of changes made within context, it is recorded in user audit
+type Actor struct {
+	ID            string
+	Source        string
+	CorrelationID string
+}
+
+type actorKey struct{}
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns actor of context, zero Actor is returned when context has no actor
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
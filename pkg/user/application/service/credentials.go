@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/outbox"
+	"github.com/google/uuid"
+
+	"userservice/pkg/common/domain"
+	"userservice/pkg/user/domain/model"
+	"userservice/pkg/user/domain/service"
+)
+
+type CredentialsService interface {
+	SetPassword(ctx context.Context, userID uuid.UUID, password string) error
+	// VerifyPassword returns identifier of user with login when password matches,
+	// unknown login and wrong password both fail with model.ErrInvalidCredentials
+	VerifyPassword(ctx context.Context, login, password string) (uuid.UUID, error)
+}
+
+func NewCredentialsService(
+	uow UnitOfWork,
+	luow LockableUnitOfWork,
+	eventDispatcher outbox.EventDispatcher[outbox.Event],
+	lockout model.PasswordLockout,
+) CredentialsService {
+	return &credentialsService{
+		uow:             uow,
+		luow:            luow,
+		eventDispatcher: eventDispatcher,
+		lockout:         lockout,
+	}
+}
+
+type credentialsService struct {
+	uow             UnitOfWork
+	luow            LockableUnitOfWork
+	eventDispatcher outbox.EventDispatcher[outbox.Event]
+	lockout         model.PasswordLockout
+}
+
+func (s *credentialsService) SetPassword(ctx context.Context, userID uuid.UUID, password string) error {
+	password, err := model.NewPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		return s.domainService(ctx, provider.UserRepository(ctx)).SetPassword(userID, password)
+	})
+}
+
+func (s *credentialsService) VerifyPassword(ctx context.Context, login, password string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := s.uow.Execute(ctx, func(provider RepositoryProvider) error {
+		canonicalLogin := model.CanonicalLogin(login)
+		user, err := provider.UserRepository(ctx).Find(model.FindSpec{TenantID: tenantSpec(ctx), Login: &canonicalLogin})
+		if err != nil {
+			return err
+		}
+		userID = user.UserID
+		return nil
+	})
+	if errors.Is(err, model.ErrUserNotFound) {
+		service.CheckDummyPassword(password)
+		return uuid.Nil, model.ErrInvalidCredentials
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	var ok bool
+	err = s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		var err error
+		ok, err = s.domainService(ctx, provider.UserRepository(ctx)).VerifyPassword(userID, password)
+		return err
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	// failed attempt is committed before error is returned
+	if !ok {
+		return uuid.Nil, model.ErrInvalidCredentials
+	}
+	return userID, nil
+}
+
+func (s *credentialsService) domainService(ctx context.Context, repository model.UserRepository) service.CredentialsService {
+	return service.NewCredentialsService(repository, s.domainEventDispatcher(ctx), s.lockout, tenantSpec(ctx))
+}
+
+func (s *credentialsService) domainEventDispatcher(ctx context.Context) domain.EventDispatcher {
+	return &domainEventDispatcher{
+		ctx:             ctx,
+		eventDispatcher: s.eventDispatcher,
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/outbox"
+	"github.com/google/uuid"
+
+	"userservice/pkg/common/domain"
+	"userservice/pkg/user/domain/model"
+	"userservice/pkg/user/domain/service"
+)
+
+type IdentityService interface {
+	// LinkIdentity links account of external identity provider to user, fails with model.ErrIdentityAlreadyLinked
+	// when account is linked to another user
+	LinkIdentity(ctx context.Context, userID uuid.UUID, issuer, subject string) error
+	UnlinkIdentity(ctx context.Context, userID uuid.UUID, issuer, subject string) error
+}
+
+func NewIdentityService(
+	luow LockableUnitOfWork,
+	eventDispatcher outbox.EventDispatcher[outbox.Event],
+) IdentityService {
+	return &identityService{
+		luow:            luow,
+		eventDispatcher: eventDispatcher,
+	}
+}
+
+type identityService struct {
+	luow            LockableUnitOfWork
+	eventDispatcher outbox.EventDispatcher[outbox.Event]
+}
+
+func (s *identityService) LinkIdentity(ctx context.Context, userID uuid.UUID, issuer, subject string) error {
+	identity, err := model.NewIdentitySpec(issuer, subject)
+	if err != nil {
+		return err
+	}
+	tenantID, _ := TenantFromContext(ctx)
+	lockNames := []string{userLock(userID), userIdentityLock(tenantID, identity)}
+	return s.luow.Execute(ctx, lockNames, func(provider RepositoryProvider) error {
+		return s.domainService(ctx, provider.UserRepository(ctx)).LinkIdentity(userID, identity)
+	})
+}
+
+func (s *identityService) UnlinkIdentity(ctx context.Context, userID uuid.UUID, issuer, subject string) error {
+	identity, err := model.NewIdentitySpec(issuer, subject)
+	if err != nil {
+		return err
+	}
+	return s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		return s.domainService(ctx, provider.UserRepository(ctx)).UnlinkIdentity(userID, identity)
+	})
+}
+
+func (s *identityService) domainService(ctx context.Context, repository model.UserRepository) service.IdentityService {
+	return service.NewIdentityService(repository, s.domainEventDispatcher(ctx), tenantSpec(ctx))
+}
+
+func (s *identityService) domainEventDispatcher(ctx context.Context) domain.EventDispatcher {
+	return &domainEventDispatcher{
+		ctx:             ctx,
+		eventDispatcher: s.eventDispatcher,
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/outbox"
+	"github.com/google/uuid"
+
+	"userservice/pkg/common/domain"
+	"userservice/pkg/user/domain/model"
+	"userservice/pkg/user/domain/service"
+)
+
+type OrganizationService interface {
+	CreateOrganization(ctx context.Context, name string, ownerID uuid.UUID) (uuid.UUID, error)
+	StoreMember(ctx context.Context, organizationID, userID uuid.UUID, role int, displayName *string) error
+	RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error
+}
+
+func NewOrganizationService(
+	luow LockableUnitOfWork,
+	eventDispatcher outbox.EventDispatcher[outbox.Event],
+) OrganizationService {
+	return &organizationService{
+		luow:            luow,
+		eventDispatcher: eventDispatcher,
+	}
+}
+
+type organizationService struct {
+	luow            LockableUnitOfWork
+	eventDispatcher outbox.EventDispatcher[outbox.Event]
+}
+
+func (s *organizationService) CreateOrganization(ctx context.Context, name string, ownerID uuid.UUID) (uuid.UUID, error) {
+	name, err := model.NewOrganizationName(name)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	// organizations are created only within tenant, domain service rejects creation when context has none
+	tenantID, _ := TenantFromContext(ctx)
+	var organizationID uuid.UUID
+	err = s.luow.Execute(ctx, []string{organizationNameLock(tenantID, name), userLock(ownerID)}, func(provider RepositoryProvider) error {
+		err := checkUserExists(provider.UserRepository(ctx), tenantSpec(ctx), ownerID)
+		if err != nil {
+			return err
+		}
+		organizationID, err = s.domainService(ctx, provider.OrganizationRepository(ctx)).CreateOrganization(name, ownerID)
+		return err
+	})
+	return organizationID, err
+}
+
+func (s *organizationService) StoreMember(ctx context.Context, organizationID, userID uuid.UUID, role int, displayName *string) error {
+	membershipRole, err := model.NewMembershipRole(role)
+	if err != nil {
+		return err
+	}
+	displayName, err = model.NewMemberDisplayName(displayName)
+	if err != nil {
+		return err
+	}
+
+	// user is locked before organization as in hard deletion of user
+	return s.luow.Execute(ctx, []string{userLock(userID), organizationLock(organizationID)}, func(provider RepositoryProvider) error {
+		err := checkUserExists(provider.UserRepository(ctx), tenantSpec(ctx), userID)
+		if err != nil {
+			return err
+		}
+		return s.domainService(ctx, provider.OrganizationRepository(ctx)).StoreMember(organizationID, userID, membershipRole, displayName)
+	})
+}
+
+func (s *organizationService) RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error {
+	return s.luow.Execute(ctx, []string{organizationLock(organizationID)}, func(provider RepositoryProvider) error {
+		return s.domainService(ctx, provider.OrganizationRepository(ctx)).RemoveMember(organizationID, userID)
+	})
+}
+
+func (s *organizationService) domainService(ctx context.Context, repository model.OrganizationRepository) service.OrganizationService {
+	return service.NewOrganizationService(repository, s.domainEventDispatcher(ctx), tenantSpec(ctx))
+}
+
+func (s *organizationService) domainEventDispatcher(ctx context.Context) domain.EventDispatcher {
+	return &domainEventDispatcher{
+		ctx:             ctx,
+		eventDispatcher: s.eventDispatcher,
+	}
+}
+
+// checkUserExists prevents deleted users from joining organizations
+func checkUserExists(repository model.UserRepository, tenantID *string, userID uuid.UUID) error {
+	user, err := repository.Find(model.FindSpec{TenantID: tenantID, UserID: &userID})
+	if err != nil {
+		return err
+	}
+	if user.Status == model.Deleted {
+		return model.ErrUserNotFound
+	}
+	return nil
+}
+
+const baseOrganizationLock = "organization_"
+
+func organizationLock(id uuid.UUID) string {
+	return baseOrganizationLock + id.String()
+}
+
+func organizationNameLock(tenantID, name string) string {
+	return baseOrganizationLock + "name_" + tenantID + "_" + strings.ToLower(name)
+}
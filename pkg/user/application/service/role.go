@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/outbox"
+
+	"userservice/pkg/common/domain"
+	"userservice/pkg/user/domain/model"
+	"userservice/pkg/user/domain/service"
+)
+
+type RoleService interface {
+	// StoreRole creates role or replaces its permissions
+	StoreRole(ctx context.Context, name string, permissions []string) error
+}
+
+func NewRoleService(
+	luow LockableUnitOfWork,
+	eventDispatcher outbox.EventDispatcher[outbox.Event],
+) RoleService {
+	return &roleService{
+		luow:            luow,
+		eventDispatcher: eventDispatcher,
+	}
+}
+
+type roleService struct {
+	luow            LockableUnitOfWork
+	eventDispatcher outbox.EventDispatcher[outbox.Event]
+}
+
+func (s *roleService) StoreRole(ctx context.Context, name string, permissions []string) error {
+	name, err := model.NewRoleName(name)
+	if err != nil {
+		return err
+	}
+	rolePermissions := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		permission, err := model.NewPermission(p)
+		if err != nil {
+			return err
+		}
+		rolePermissions = append(rolePermissions, permission)
+	}
+
+	return s.luow.Execute(ctx, []string{roleLock(name)}, func(provider RepositoryProvider) error {
+		return s.domainService(ctx, provider.RoleRepository(ctx)).StoreRole(name, rolePermissions)
+	})
+}
+
+func (s *roleService) domainService(ctx context.Context, repository model.RoleRepository) service.RoleService {
+	return service.NewRoleService(repository, s.domainEventDispatcher(ctx))
+}
+
+func (s *roleService) domainEventDispatcher(ctx context.Context) domain.EventDispatcher {
+	return &domainEventDispatcher{
+		ctx:             ctx,
+		eventDispatcher: s.eventDispatcher,
+	}
+}
+
+const baseRoleLock = "role_"
+
+func roleLock(name string) string {
+	return baseRoleLock + name
+}
@@ -0,0 +1,25 @@
+package service
+
+import "context"
+
+type tenantKey struct{}
+
+// WithTenant restricts users visible to services within context to users of tenant
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns tenant of context, ok is false for callers acting on users of all tenants, e.g. workflows
+func TenantFromContext(ctx context.Context) (tenantID string, ok bool) {
+	tenantID, ok = ctx.Value(tenantKey{}).(string)
+	return tenantID, ok
+}
+
+// tenantSpec returns tenant of context in the form used by model.FindSpec
+func tenantSpec(ctx context.Context) *string {
+	tenantID, ok := TenantFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return &tenantID
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/outbox"
+	"github.com/google/uuid"
+
+	"userservice/pkg/common/domain"
+	"userservice/pkg/user/domain/model"
+	"userservice/pkg/user/domain/service"
+)
+
+type APITokenService interface {
+	// CreateAPIToken returns identifier and token, token can not be retrieved later
+	CreateAPIToken(ctx context.Context, userID uuid.UUID, name string, scopes []string, expiresAt *time.Time) (uuid.UUID, string, error)
+	RevokeAPIToken(ctx context.Context, userID, tokenID uuid.UUID) error
+	// ValidateAPIToken returns owner and scopes of token,
+	// unknown, expired and revoked tokens all fail with model.ErrInvalidAPIToken
+	ValidateAPIToken(ctx context.Context, token string) (uuid.UUID, []string, error)
+}
+
+func NewAPITokenService(
+	uow UnitOfWork,
+	luow LockableUnitOfWork,
+	eventDispatcher outbox.EventDispatcher[outbox.Event],
+) APITokenService {
+	return &apiTokenService{
+		uow:             uow,
+		luow:            luow,
+		eventDispatcher: eventDispatcher,
+	}
+}
+
+type apiTokenService struct {
+	uow             UnitOfWork
+	luow            LockableUnitOfWork
+	eventDispatcher outbox.EventDispatcher[outbox.Event]
+}
+
+func (s *apiTokenService) CreateAPIToken(ctx context.Context, userID uuid.UUID, name string, scopes []string, expiresAt *time.Time) (uuid.UUID, string, error) {
+	name, err := model.NewAPITokenName(name)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	scopes, err = model.NewAPITokenScopes(scopes)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+
+	var (
+		tokenID uuid.UUID
+		token   string
+	)
+	err = s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		var err error
+		tokenID, token, err = s.domainService(ctx, provider.UserRepository(ctx)).CreateAPIToken(userID, name, scopes, expiresAt)
+		return err
+	})
+	return tokenID, token, err
+}
+
+func (s *apiTokenService) RevokeAPIToken(ctx context.Context, userID, tokenID uuid.UUID) error {
+	return s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		return s.domainService(ctx, provider.UserRepository(ctx)).RevokeAPIToken(userID, tokenID)
+	})
+}
+
+func (s *apiTokenService) ValidateAPIToken(ctx context.Context, token string) (uuid.UUID, []string, error) {
+	var (
+		userID uuid.UUID
+		scopes []string
+	)
+	err := s.uow.Execute(ctx, func(provider RepositoryProvider) error {
+		var err error
+		userID, scopes, err = s.domainService(ctx, provider.UserRepository(ctx)).ValidateAPIToken(token)
+		return err
+	})
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	return userID, scopes, nil
+}
+
+func (s *apiTokenService) domainService(ctx context.Context, repository model.UserRepository) service.APITokenService {
+	return service.NewAPITokenService(repository, s.domainEventDispatcher(ctx), tenantSpec(ctx))
+}
+
+func (s *apiTokenService) domainEventDispatcher(ctx context.Context) domain.EventDispatcher {
+	return &domainEventDispatcher{
+		ctx:             ctx,
+		eventDispatcher: s.eventDispatcher,
+	}
+}
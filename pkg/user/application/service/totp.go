@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/outbox"
+	"github.com/google/uuid"
+
+	"userservice/pkg/common/domain"
+	"userservice/pkg/user/domain/model"
+	"userservice/pkg/user/domain/service"
+)
+
+type TOTPService interface {
+	// EnrollTOTP returns secret and otpauth URI, enrollment takes effect after ConfirmTOTP
+	EnrollTOTP(ctx context.Context, userID uuid.UUID) (secret, uri string, err error)
+	// ConfirmTOTP returns one-time recovery codes which can not be retrieved later
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	// VerifyTOTP accepts code of authenticator app or recovery code, mismatch fails with model.ErrInvalidTOTPCode
+	VerifyTOTP(ctx context.Context, userID uuid.UUID, code string) error
+	// RemoveTOTP requires code of confirmed factor as VerifyTOTP does, mismatch fails with model.ErrInvalidTOTPCode
+	RemoveTOTP(ctx context.Context, userID uuid.UUID, code string) error
+}
+
+// NewTOTPService returns service failing with model.ErrTOTPNotConfigured on enrollment and verification when cipher is nil
+func NewTOTPService(
+	luow LockableUnitOfWork,
+	eventDispatcher outbox.EventDispatcher[outbox.Event],
+	cipher service.SecretCipher,
+	issuer string,
+	lockout model.PasswordLockout,
+) TOTPService {
+	return &totpService{
+		luow:            luow,
+		eventDispatcher: eventDispatcher,
+		cipher:          cipher,
+		issuer:          issuer,
+		lockout:         lockout,
+	}
+}
+
+type totpService struct {
+	luow            LockableUnitOfWork
+	eventDispatcher outbox.EventDispatcher[outbox.Event]
+	cipher          service.SecretCipher
+	issuer          string
+	lockout         model.PasswordLockout
+}
+
+func (s *totpService) EnrollTOTP(ctx context.Context, userID uuid.UUID) (string, string, error) {
+	var secret, uri string
+	err := s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		var err error
+		secret, uri, err = s.domainService(ctx, provider.UserRepository(ctx)).EnrollTOTP(userID)
+		return err
+	})
+	return secret, uri, err
+}
+
+func (s *totpService) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	var recoveryCodes []string
+	err := s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		var err error
+		recoveryCodes, err = s.domainService(ctx, provider.UserRepository(ctx)).ConfirmTOTP(userID, code)
+		return err
+	})
+	return recoveryCodes, err
+}
+
+func (s *totpService) VerifyTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	var ok bool
+	err := s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		var err error
+		ok, err = s.domainService(ctx, provider.UserRepository(ctx)).VerifyTOTP(userID, code)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	// failed attempt is committed before error is returned
+	if !ok {
+		return model.ErrInvalidTOTPCode
+	}
+	return nil
+}
+
+func (s *totpService) RemoveTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	var ok bool
+	err := s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		var err error
+		ok, err = s.domainService(ctx, provider.UserRepository(ctx)).RemoveTOTP(userID, code)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	// failed attempt is committed before error is returned
+	if !ok {
+		return model.ErrInvalidTOTPCode
+	}
+	return nil
+}
+
+func (s *totpService) domainService(ctx context.Context, repository model.UserRepository) service.TOTPService {
+	return service.NewTOTPService(repository, s.domainEventDispatcher(ctx), s.cipher, s.issuer, s.lockout, tenantSpec(ctx))
+}
+
+func (s *totpService) domainEventDispatcher(ctx context.Context) domain.EventDispatcher {
+	return &domainEventDispatcher{
+		ctx:             ctx,
+		eventDispatcher: s.eventDispatcher,
+	}
+}
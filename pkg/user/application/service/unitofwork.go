@@ -8,6 +8,8 @@ import (
 
 type RepositoryProvider interface {
 	UserRepository(ctx context.Context) model.UserRepository
+	RoleRepository(ctx context.Context) model.RoleRepository
+	OrganizationRepository(ctx context.Context) model.OrganizationRepository
 }
 
 type LockableUnitOfWork interface {
@@ -2,6 +2,11 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"time"
 
 	"gitea.xscloud.ru/xscloud/golib/pkg/application/outbox"
 	"github.com/google/uuid"
@@ -14,7 +19,24 @@ import (
 
 type UserService interface {
 	StoreUser(ctx context.Context, user appmodel.User) (uuid.UUID, error)
-	SetUserStatus(ctx context.Context, userID uuid.UUID, status int) error
+	// SetUserStatus sets user status manually, status with expiresAt is reverted to previous one when it expires
+	SetUserStatus(ctx context.Context, userID uuid.UUID, status int, expiresAt *time.Time) error
+	// SetSystemUserStatus sets status computed by the system, fails with model.ErrUserStatusOverridden when status is set manually
+	SetSystemUserStatus(ctx context.Context, userID uuid.UUID, status int) error
+	ExpireUserStatus(ctx context.Context, userID uuid.UUID, expiresAt time.Time) error
+	ClearUserStatusOverride(ctx context.Context, userID uuid.UUID) error
+	VerifyContact(ctx context.Context, userID uuid.UUID, contactType int, value, code string) error
+	ResendContactVerification(ctx context.Context, userID uuid.UUID, contactType int, value string) error
+	SetUserAttributes(ctx context.Context, userID uuid.UUID, attributes map[string]string, labels []string) error
+	// SetNotificationPreferences replaces notification preferences of user with full set of preferences
+	SetNotificationPreferences(ctx context.Context, userID uuid.UUID, preferences []appmodel.NotificationPreference) error
+	DeleteUser(ctx context.Context, userID uuid.UUID, hard bool) error
+	RestoreUser(ctx context.Context, userID uuid.UUID) error
+	// MergeUsers moves contacts, attributes, labels, notification preferences, roles, linked identities
+	// and organization memberships of source user to target user and deletes source user
+	MergeUsers(ctx context.Context, sourceID, targetID uuid.UUID) error
+	AssignRole(ctx context.Context, userID uuid.UUID, role string) error
+	RevokeRole(ctx context.Context, userID uuid.UUID, role string) error
 	FindUser(ctx context.Context, userID uuid.UUID) (appmodel.User, error)
 }
 
@@ -22,79 +44,312 @@ func NewUserService(
 	uow UnitOfWork,
 	luow LockableUnitOfWork,
 	eventDispatcher outbox.EventDispatcher[outbox.Event],
+	attributeSchema model.AttributeSchema,
+	emailNormalization model.EmailNormalization,
 ) UserService {
 	return &userService{
-		uow:             uow,
-		luow:            luow,
-		eventDispatcher: eventDispatcher,
+		uow:                uow,
+		luow:               luow,
+		eventDispatcher:    eventDispatcher,
+		attributeSchema:    attributeSchema,
+		emailNormalization: emailNormalization,
 	}
 }
 
 type userService struct {
-	uow             UnitOfWork
-	luow            LockableUnitOfWork
-	eventDispatcher outbox.EventDispatcher[outbox.Event]
+	uow                UnitOfWork
+	luow               LockableUnitOfWork
+	eventDispatcher    outbox.EventDispatcher[outbox.Event]
+	attributeSchema    model.AttributeSchema
+	emailNormalization model.EmailNormalization
 }
 
 func (s *userService) StoreUser(ctx context.Context, user appmodel.User) (uuid.UUID, error) {
+	profile, err := model.NewProfile(user.DisplayName, user.Locale, user.Timezone, user.AvatarURL)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	contacts := make([]model.Contact, 0, len(user.Contacts))
+	for _, c := range user.Contacts {
+		contact, err := model.NewContact(model.ContactType(c.Type), c.Value, c.Primary, s.emailNormalization)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+
+	// users are created only within tenant, domain service rejects creation when context has none
+	tenantID, _ := TenantFromContext(ctx)
 	var lockNames []string
 	if user.UserID != uuid.Nil {
 		lockNames = append(lockNames, userLock(user.UserID))
 	} else {
-		lockNames = append(lockNames, userLoginLock(user.Login))
-	}
-	if user.Email != nil {
-		lockNames = append(lockNames, userEmailLock(*user.Email))
+		lockNames = append(lockNames, userLoginLock(tenantID, user.Login))
 	}
-	if user.Telegram != nil {
-		lockNames = append(lockNames, userTelegramLock(*user.Telegram))
+	// contact locks are taken in the same order regardless of order of contacts in request to avoid deadlock
+	contactLockNames := make([]string, 0, len(contacts))
+	for _, contact := range contacts {
+		contactLockNames = append(contactLockNames, userContactLock(tenantID, contact))
 	}
+	sort.Strings(contactLockNames)
+	lockNames = append(lockNames, contactLockNames...)
 
 	userID := user.UserID
-	err := s.luow.Execute(ctx, lockNames, func(provider RepositoryProvider) error {
+	err = s.luow.Execute(ctx, lockNames, func(provider RepositoryProvider) error {
 		domainService := s.domainService(ctx, provider.UserRepository(ctx))
-		if user.UserID == uuid.Nil {
-			uID, err := domainService.CreateUser(user.Login)
+		if user.UserID != uuid.Nil {
+			return domainService.UpdateUser(userID, profile, contacts)
+		}
+		uID, err := domainService.CreateUser(user.Login, profile, contacts)
+		if err != nil {
+			return err
+		}
+		userID = uID
+		return nil
+	})
+	return userID, err
+}
+
+func (s *userService) SetUserStatus(ctx context.Context, userID uuid.UUID, status int, expiresAt *time.Time) error {
+	userStatus, err := model.NewUserStatus(status)
+	if err != nil {
+		return err
+	}
+	if expiresAt != nil {
+		// expiration is stored and published with seconds precision
+		t := expiresAt.Truncate(time.Second)
+		expiresAt = &t
+	}
+	return s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		return s.domainService(ctx, provider.UserRepository(ctx)).UpdateUserStatus(userID, userStatus, model.StatusSourceManual, expiresAt)
+	})
+}
+
+func (s *userService) SetSystemUserStatus(ctx context.Context, userID uuid.UUID, status int) error {
+	userStatus, err := model.NewUserStatus(status)
+	if err != nil {
+		return err
+	}
+	return s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		return s.domainService(ctx, provider.UserRepository(ctx)).UpdateUserStatus(userID, userStatus, model.StatusSourceSystem, nil)
+	})
+}
+
+func (s *userService) ExpireUserStatus(ctx context.Context, userID uuid.UUID, expiresAt time.Time) error {
+	return s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		return s.domainService(ctx, provider.UserRepository(ctx)).ExpireUserStatus(userID, expiresAt)
+	})
+}
+
+func (s *userService) ClearUserStatusOverride(ctx context.Context, userID uuid.UUID) error {
+	return s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		return s.domainService(ctx, provider.UserRepository(ctx)).ClearStatusOverride(userID)
+	})
+}
+
+func (s *userService) VerifyContact(ctx context.Context, userID uuid.UUID, contactType int, value, code string) error {
+	contact, err := model.NewContact(model.ContactType(contactType), value, false, s.emailNormalization)
+	if err != nil {
+		return err
+	}
+	var ok bool
+	err = s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		var err error
+		ok, err = s.domainService(ctx, provider.UserRepository(ctx)).VerifyContact(userID, contact.Type, contact.Canonical, code)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	// failed attempt is committed before error is returned
+	if !ok {
+		return model.ErrInvalidContactVerificationCode
+	}
+	return nil
+}
+
+func (s *userService) ResendContactVerification(ctx context.Context, userID uuid.UUID, contactType int, value string) error {
+	contact, err := model.NewContact(model.ContactType(contactType), value, false, s.emailNormalization)
+	if err != nil {
+		return err
+	}
+	return s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		return s.domainService(ctx, provider.UserRepository(ctx)).ResendContactVerification(userID, contact.Type, contact.Canonical)
+	})
+}
+
+func (s *userService) SetUserAttributes(ctx context.Context, userID uuid.UUID, attributes map[string]string, labels []string) error {
+	domainAttributes := make([]model.Attribute, 0, len(attributes))
+	for key, value := range attributes {
+		attribute, err := s.attributeSchema.NewAttribute(key, value)
+		if err != nil {
+			return err
+		}
+		domainAttributes = append(domainAttributes, attribute)
+	}
+	sort.Slice(domainAttributes, func(i, j int) bool {
+		return domainAttributes[i].Key < domainAttributes[j].Key
+	})
+
+	domainLabels := make([]string, 0, len(labels))
+	for _, l := range labels {
+		label, err := model.NewLabel(l)
+		if err != nil {
+			return err
+		}
+		domainLabels = append(domainLabels, label)
+	}
+
+	return s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		return s.domainService(ctx, provider.UserRepository(ctx)).UpdateUserAttributes(userID, domainAttributes, domainLabels)
+	})
+}
+
+func (s *userService) SetNotificationPreferences(ctx context.Context, userID uuid.UUID, preferences []appmodel.NotificationPreference) error {
+	domainPreferences := make([]model.NotificationPreference, 0, len(preferences))
+	for _, p := range preferences {
+		preference, err := model.NewNotificationPreference(model.ContactType(p.Channel), p.Category, p.Enabled)
+		if err != nil {
+			return err
+		}
+		domainPreferences = append(domainPreferences, preference)
+	}
+
+	return s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		return s.domainService(ctx, provider.UserRepository(ctx)).UpdateNotificationPreferences(userID, domainPreferences)
+	})
+}
+
+// DeleteUser with hard removes user from organizations too, user can not join organizations while user lock is held,
+// so organizations are locked after memberships of user are found
+func (s *userService) DeleteUser(ctx context.Context, userID uuid.UUID, hard bool) error {
+	return s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		if !hard {
+			return s.domainService(ctx, provider.UserRepository(ctx)).DeleteUser(userID, hard)
+		}
+
+		organizations, err := provider.OrganizationRepository(ctx).FindByMember(userID)
+		if err != nil {
+			return err
+		}
+		if len(organizations) == 0 {
+			return s.domainService(ctx, provider.UserRepository(ctx)).DeleteUser(userID, hard)
+		}
+		lockNames := make([]string, 0, len(organizations))
+		for _, organization := range organizations {
+			lockNames = append(lockNames, organizationLock(organization.OrganizationID))
+		}
+		return s.luow.Execute(ctx, lockNames, func(provider RepositoryProvider) error {
+			err := service.NewOrganizationService(provider.OrganizationRepository(ctx), s.domainEventDispatcher(ctx), tenantSpec(ctx)).
+				RemoveUserMemberships(userID)
 			if err != nil {
 				return err
 			}
-			userID = uID
-		}
+			return s.domainService(ctx, provider.UserRepository(ctx)).DeleteUser(userID, hard)
+		})
+	})
+}
 
-		err := domainService.UpdateUserEmail(userID, user.Email)
+func (s *userService) RestoreUser(ctx context.Context, userID uuid.UUID) error {
+	return s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		return s.domainService(ctx, provider.UserRepository(ctx)).RestoreUser(userID)
+	})
+}
+
+func (s *userService) MergeUsers(ctx context.Context, sourceID, targetID uuid.UUID) error {
+	// locks are taken in the same order regardless of merge direction to avoid deadlock of opposite merges
+	lockNames := []string{userLock(sourceID), userLock(targetID)}
+	sort.Strings(lockNames)
+	return s.luow.Execute(ctx, lockNames, func(provider RepositoryProvider) error {
+		organizations, err := provider.OrganizationRepository(ctx).FindByMember(sourceID)
 		if err != nil {
 			return err
 		}
+		if len(organizations) == 0 {
+			return s.domainService(ctx, provider.UserRepository(ctx)).MergeUsers(sourceID, targetID, nil)
+		}
+		organizationLockNames := make([]string, 0, len(organizations))
+		for _, organization := range organizations {
+			organizationLockNames = append(organizationLockNames, organizationLock(organization.OrganizationID))
+		}
+		return s.luow.Execute(ctx, organizationLockNames, func(provider RepositoryProvider) error {
+			organizationIDs, err := service.NewOrganizationService(provider.OrganizationRepository(ctx), s.domainEventDispatcher(ctx), tenantSpec(ctx)).
+				TransferUserMemberships(sourceID, targetID)
+			if err != nil {
+				return err
+			}
+			return s.domainService(ctx, provider.UserRepository(ctx)).MergeUsers(sourceID, targetID, organizationIDs)
+		})
+	})
+}
 
-		err = domainService.UpdateUserTelegram(userID, user.Telegram)
+func (s *userService) AssignRole(ctx context.Context, userID uuid.UUID, role string) error {
+	role, err := model.NewRoleName(role)
+	if err != nil {
+		return err
+	}
+	return s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
+		domainRole, err := provider.RoleRepository(ctx).Find(role)
 		if err != nil {
 			return err
 		}
-
-		return nil
+		return s.domainService(ctx, provider.UserRepository(ctx)).AssignRole(userID, *domainRole)
 	})
-	return userID, err
 }
 
-func (s *userService) SetUserStatus(ctx context.Context, userID uuid.UUID, status int) error {
+func (s *userService) RevokeRole(ctx context.Context, userID uuid.UUID, role string) error {
+	role, err := model.NewRoleName(role)
+	if err != nil {
+		return err
+	}
 	return s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
-		return s.domainService(ctx, provider.UserRepository(ctx)).UpdateUserStatus(userID, model.UserStatus(status))
+		return s.domainService(ctx, provider.UserRepository(ctx)).RevokeRole(userID, role)
 	})
 }
 
 func (s *userService) FindUser(ctx context.Context, userID uuid.UUID) (appmodel.User, error) {
 	var user appmodel.User
 	err := s.luow.Execute(ctx, []string{userLock(userID)}, func(provider RepositoryProvider) error {
-		domainUser, err := provider.UserRepository(ctx).Find(model.FindSpec{UserID: &userID})
+		domainUser, err := provider.UserRepository(ctx).Find(model.FindSpec{TenantID: tenantSpec(ctx), UserID: &userID})
 		if err != nil {
 			return err
 		}
 		user = appmodel.User{
-			UserID:   domainUser.UserID,
-			Status:   int(domainUser.Status),
-			Login:    domainUser.Login,
-			Email:    domainUser.Email,
-			Telegram: domainUser.Telegram,
+			UserID:       domainUser.UserID,
+			TenantID:     domainUser.TenantID,
+			Status:       int(domainUser.Status),
+			StatusSource: int(domainUser.StatusSource),
+			Login:        domainUser.Login,
+			DisplayName:  domainUser.Profile.DisplayName,
+			Locale:       domainUser.Profile.Locale,
+			Timezone:     domainUser.Profile.Timezone,
+			AvatarURL:    domainUser.Profile.AvatarURL,
+			Contacts:     make([]appmodel.Contact, 0, len(domainUser.Contacts)),
+			Attributes:   make(map[string]string, len(domainUser.Attributes)),
+			Labels:       domainUser.Labels,
+			Roles:        domainUser.Roles,
+			MergedInto:   domainUser.MergedInto,
+		}
+		if domainUser.StatusExpiration != nil {
+			user.StatusExpiresAt = &domainUser.StatusExpiration.ExpiresAt
+		}
+		for _, attribute := range domainUser.Attributes {
+			user.Attributes[attribute.Key] = attribute.Value
+		}
+		for _, contact := range domainUser.Contacts {
+			user.Contacts = append(user.Contacts, appmodel.Contact{
+				Type:       int(contact.Type),
+				Value:      contact.Value,
+				Primary:    contact.Primary,
+				VerifiedAt: contact.VerifiedAt,
+			})
+		}
+		for _, preference := range domainUser.NotificationPreferences {
+			user.NotificationPreferences = append(user.NotificationPreferences, appmodel.NotificationPreference{
+				Channel:  int(preference.Channel),
+				Category: preference.Category,
+				Enabled:  preference.Enabled,
+			})
 		}
 		return nil
 	})
@@ -102,7 +357,7 @@ func (s *userService) FindUser(ctx context.Context, userID uuid.UUID) (appmodel.
 }
 
 func (s *userService) domainService(ctx context.Context, repository model.UserRepository) service.UserService {
-	return service.NewUserService(repository, s.domainEventDispatcher(ctx))
+	return service.NewUserService(repository, s.domainEventDispatcher(ctx), tenantSpec(ctx))
 }
 
 func (s *userService) domainEventDispatcher(ctx context.Context) domain.EventDispatcher {
@@ -114,18 +369,21 @@ func (s *userService) domainEventDispatcher(ctx context.Context) domain.EventDis
 
 const baseUserLock = "user_"
 
+// userLock has no tenant as user IDs are unique across tenants and workflows lock users without knowing their tenant
 func userLock(id uuid.UUID) string {
 	return baseUserLock + id.String()
 }
 
-func userLoginLock(login string) string {
-	return baseUserLock + "login_" + login
+func userLoginLock(tenantID, login string) string {
+	return baseUserLock + "login_" + tenantID + "_" + model.CanonicalLogin(login)
 }
 
-func userEmailLock(email string) string {
-	return baseUserLock + "email_" + email
+func userContactLock(tenantID string, contact model.Contact) string {
+	return baseUserLock + "contact_" + tenantID + "_" + strconv.Itoa(int(contact.Type)) + "_" + contact.Canonical
 }
 
-func userTelegramLock(telegram string) string {
-	return baseUserLock + "telegram_" + telegram
+// userIdentityLock hashes identity as lock names are cut to 64 characters and issuers share long prefixes
+func userIdentityLock(tenantID string, identity model.IdentitySpec) string {
+	hash := sha256.Sum256([]byte(tenantID + "\n" + identity.Issuer + "\n" + identity.Subject))
+	return baseUserLock + "identity_" + hex.EncodeToString(hash[:16])
 }
@@ -0,0 +1,535 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"userservice/api/server/userpublicapi"
+	"userservice/pkg/user/domain/model"
+)
+
+type Config struct {
+	Address string
+	// TenantID is passed with every request, service uses default tenant when it is empty
+	TenantID string
+	// MaxRetries is the number of additional attempts of read-only methods made when the service is unavailable,
+	// methods changing state are not retried as failed request may have been applied
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// DialOptions are appended to default options, insecure transport credentials are used when none passed
+	DialOptions []grpc.DialOption
+}
+
+type User struct {
+	UserID uuid.UUID
+	Status model.UserStatus
+	// StatusSource and StatusExpiresAt are read-only, status is managed through admin API
+	StatusSource    model.StatusSource
+	StatusExpiresAt *time.Time
+	Login           string
+	Profile         model.Profile
+	Contacts        []model.Contact
+	// Attributes and Labels are ignored by StoreUser, use SetUserAttributes to change them
+	Attributes map[string]string
+	Labels     []string
+	// NotificationPreferences are ignored by StoreUser, use SetNotificationPreferences to change them
+	NotificationPreferences []model.NotificationPreference
+	// Roles are read-only, roles are managed through admin API
+	Roles []string
+	// Memberships are read-only, use organization methods to change them
+	Memberships []Membership
+	// MergedInto is set for deleted user merged into another user, it is read-only
+	MergedInto *uuid.UUID
+}
+
+type Membership struct {
+	OrganizationID uuid.UUID
+	Role           model.MembershipRole
+	DisplayName    *string
+}
+
+// APIToken describes api token of user, token itself is returned only by CreateAPIToken
+type APIToken struct {
+	TokenID    uuid.UUID
+	Name       string
+	Scopes     []string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+type UserClient interface {
+	StoreUser(ctx context.Context, user User) (uuid.UUID, error)
+	FindUser(ctx context.Context, userID uuid.UUID) (User, error)
+	// FindUserByIdentity fails with model.ErrUserNotFound when no active user is linked to identity
+	FindUserByIdentity(ctx context.Context, issuer, subject string) (User, error)
+	VerifyContact(ctx context.Context, userID uuid.UUID, contact model.ContactSpec, code string) error
+	ResendVerification(ctx context.Context, userID uuid.UUID, contact model.ContactSpec) error
+	SetUserAttributes(ctx context.Context, userID uuid.UUID, attributes map[string]string, labels []string) error
+	// SetNotificationPreferences replaces all notification preferences of user
+	SetNotificationPreferences(ctx context.Context, userID uuid.UUID, preferences []model.NotificationPreference) error
+	CheckPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error)
+	SetPassword(ctx context.Context, userID uuid.UUID, password string) error
+	// VerifyPassword returns identifier of user with login, fails with model.ErrInvalidCredentials when password does not match
+	VerifyPassword(ctx context.Context, login, password string) (uuid.UUID, error)
+	// CreateAPIToken returns identifier and token, token can not be retrieved later
+	CreateAPIToken(ctx context.Context, userID uuid.UUID, name string, scopes []string, expiresAt *time.Time) (uuid.UUID, string, error)
+	ListAPITokens(ctx context.Context, userID uuid.UUID) ([]APIToken, error)
+	RevokeAPIToken(ctx context.Context, userID, tokenID uuid.UUID) error
+	// ValidateToken returns owner and scopes of api token, fails with model.ErrInvalidAPIToken for unknown, expired and revoked tokens
+	ValidateToken(ctx context.Context, token string) (uuid.UUID, []string, error)
+	// EnrollTOTP returns secret and otpauth URI, enrollment takes effect after ConfirmTOTP
+	EnrollTOTP(ctx context.Context, userID uuid.UUID) (secret, uri string, err error)
+	// ConfirmTOTP returns one-time recovery codes which can not be retrieved later
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	// VerifyTOTP fails with model.ErrInvalidTOTPCode when code does not match
+	VerifyTOTP(ctx context.Context, userID uuid.UUID, code string) error
+	// RemoveTOTP requires current code or recovery code of confirmed factor and fails with model.ErrInvalidTOTPCode on mismatch
+	RemoveTOTP(ctx context.Context, userID uuid.UUID, code string) error
+	// LinkIdentity fails with model.ErrIdentityAlreadyLinked when identity is linked to another user
+	LinkIdentity(ctx context.Context, userID uuid.UUID, issuer, subject string) error
+	UnlinkIdentity(ctx context.Context, userID uuid.UUID, issuer, subject string) error
+	CreateOrganization(ctx context.Context, name string, ownerID uuid.UUID) (uuid.UUID, error)
+	FindOrganization(ctx context.Context, organizationID uuid.UUID) (model.Organization, error)
+	StoreMember(ctx context.Context, organizationID uuid.UUID, member model.Member) error
+	RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error
+}
+
+type Client interface {
+	UserClient
+	Close() error
+}
+
+func NewClient(config Config) (Client, error) {
+	if config.InitialBackoff == 0 {
+		config.InitialBackoff = 100 * time.Millisecond
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = 5 * time.Second
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(
+			newErrorsInterceptor(),
+			newTenantInterceptor(config.TenantID),
+			newRetryInterceptor(config.MaxRetries, config.InitialBackoff, config.MaxBackoff),
+		),
+	}
+	opts = append(opts, config.DialOptions...)
+
+	conn, err := grpc.NewClient(config.Address, opts...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &client{
+		conn: conn,
+		api:  userpublicapi.NewUserPublicAPIClient(conn),
+	}, nil
+}
+
+type client struct {
+	conn *grpc.ClientConn
+	api  userpublicapi.UserPublicAPIClient
+}
+
+func (c *client) StoreUser(ctx context.Context, user User) (uuid.UUID, error) {
+	request := &userpublicapi.StoreUserRequest{
+		Login:       user.Login,
+		DisplayName: user.Profile.DisplayName,
+		Locale:      user.Profile.Locale,
+		Timezone:    user.Profile.Timezone,
+		AvatarURL:   user.Profile.AvatarURL,
+		Contacts:    make([]*userpublicapi.Contact, 0, len(user.Contacts)),
+	}
+	for _, contact := range user.Contacts {
+		request.Contacts = append(request.Contacts, &userpublicapi.Contact{
+			Type:    userpublicapi.ContactType(contact.Type), // nolint:gosec
+			Value:   contact.Value,
+			Primary: contact.Primary,
+		})
+	}
+	if user.UserID != uuid.Nil {
+		request.UserID = user.UserID.String()
+	}
+
+	response, err := c.api.StoreUser(ctx, request)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.Parse(response.UserID)
+}
+
+func (c *client) FindUser(ctx context.Context, userID uuid.UUID) (User, error) {
+	response, err := c.api.FindUser(ctx, &userpublicapi.FindUserRequest{
+		UserID: userID.String(),
+	})
+	if err != nil {
+		return User{}, err
+	}
+	return fromFindUserResponse(response)
+}
+
+func (c *client) FindUserByIdentity(ctx context.Context, issuer, subject string) (User, error) {
+	response, err := c.api.FindUserByIdentity(ctx, &userpublicapi.FindUserByIdentityRequest{
+		Issuer:  issuer,
+		Subject: subject,
+	})
+	if err != nil {
+		return User{}, err
+	}
+	return fromFindUserResponse(response)
+}
+
+func (c *client) VerifyContact(ctx context.Context, userID uuid.UUID, contact model.ContactSpec, code string) error {
+	_, err := c.api.VerifyContact(ctx, &userpublicapi.VerifyContactRequest{
+		UserID: userID.String(),
+		Type:   userpublicapi.ContactType(contact.Type), // nolint:gosec
+		Value:  contact.Value,
+		Code:   code,
+	})
+	return err
+}
+
+func (c *client) ResendVerification(ctx context.Context, userID uuid.UUID, contact model.ContactSpec) error {
+	_, err := c.api.ResendVerification(ctx, &userpublicapi.ResendVerificationRequest{
+		UserID: userID.String(),
+		Type:   userpublicapi.ContactType(contact.Type), // nolint:gosec
+		Value:  contact.Value,
+	})
+	return err
+}
+
+func (c *client) SetUserAttributes(ctx context.Context, userID uuid.UUID, attributes map[string]string, labels []string) error {
+	_, err := c.api.SetUserAttributes(ctx, &userpublicapi.SetUserAttributesRequest{
+		UserID:     userID.String(),
+		Attributes: attributes,
+		Labels:     labels,
+	})
+	return err
+}
+
+func (c *client) SetNotificationPreferences(ctx context.Context, userID uuid.UUID, preferences []model.NotificationPreference) error {
+	request := &userpublicapi.SetNotificationPreferencesRequest{
+		UserID:      userID.String(),
+		Preferences: make([]*userpublicapi.NotificationPreference, 0, len(preferences)),
+	}
+	for _, preference := range preferences {
+		request.Preferences = append(request.Preferences, &userpublicapi.NotificationPreference{
+			Channel:  userpublicapi.ContactType(preference.Channel), // nolint:gosec
+			Category: preference.Category,
+			Enabled:  preference.Enabled,
+		})
+	}
+	_, err := c.api.SetNotificationPreferences(ctx, request)
+	return err
+}
+
+func (c *client) CheckPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
+	response, err := c.api.CheckPermission(ctx, &userpublicapi.CheckPermissionRequest{
+		UserID:     userID.String(),
+		Permission: permission,
+	})
+	if err != nil {
+		return false, err
+	}
+	return response.Granted, nil
+}
+
+func (c *client) SetPassword(ctx context.Context, userID uuid.UUID, password string) error {
+	_, err := c.api.SetPassword(ctx, &userpublicapi.SetPasswordRequest{
+		UserID:   userID.String(),
+		Password: password,
+	})
+	return err
+}
+
+func (c *client) VerifyPassword(ctx context.Context, login, password string) (uuid.UUID, error) {
+	response, err := c.api.VerifyPassword(ctx, &userpublicapi.VerifyPasswordRequest{
+		Login:    login,
+		Password: password,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.Parse(response.UserID)
+}
+
+func (c *client) CreateAPIToken(ctx context.Context, userID uuid.UUID, name string, scopes []string, expiresAt *time.Time) (uuid.UUID, string, error) {
+	request := &userpublicapi.CreateAPITokenRequest{
+		UserID: userID.String(),
+		Name:   name,
+		Scopes: scopes,
+	}
+	if expiresAt != nil {
+		unix := expiresAt.Unix()
+		request.ExpiresAt = &unix
+	}
+	response, err := c.api.CreateAPIToken(ctx, request)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	tokenID, err := uuid.Parse(response.TokenID)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	return tokenID, response.Token, nil
+}
+
+func (c *client) ListAPITokens(ctx context.Context, userID uuid.UUID) ([]APIToken, error) {
+	response, err := c.api.ListAPITokens(ctx, &userpublicapi.ListAPITokensRequest{
+		UserID: userID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]APIToken, 0, len(response.Tokens))
+	for _, token := range response.Tokens {
+		tokenID, err := uuid.Parse(token.TokenID)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, APIToken{
+			TokenID:    tokenID,
+			Name:       token.Name,
+			Scopes:     token.Scopes,
+			ExpiresAt:  fromUnix(token.ExpiresAt),
+			LastUsedAt: fromUnix(token.LastUsedAt),
+			CreatedAt:  time.Unix(token.CreatedAt, 0),
+		})
+	}
+	return tokens, nil
+}
+
+func (c *client) RevokeAPIToken(ctx context.Context, userID, tokenID uuid.UUID) error {
+	_, err := c.api.RevokeAPIToken(ctx, &userpublicapi.RevokeAPITokenRequest{
+		UserID:  userID.String(),
+		TokenID: tokenID.String(),
+	})
+	return err
+}
+
+func (c *client) ValidateToken(ctx context.Context, token string) (uuid.UUID, []string, error) {
+	response, err := c.api.ValidateToken(ctx, &userpublicapi.ValidateTokenRequest{
+		Token: token,
+	})
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	userID, err := uuid.Parse(response.UserID)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	return userID, response.Scopes, nil
+}
+
+func (c *client) EnrollTOTP(ctx context.Context, userID uuid.UUID) (string, string, error) {
+	response, err := c.api.EnrollTOTP(ctx, &userpublicapi.EnrollTOTPRequest{
+		UserID: userID.String(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return response.Secret, response.Uri, nil
+}
+
+func (c *client) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	response, err := c.api.ConfirmTOTP(ctx, &userpublicapi.ConfirmTOTPRequest{
+		UserID: userID.String(),
+		Code:   code,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response.RecoveryCodes, nil
+}
+
+func (c *client) VerifyTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	_, err := c.api.VerifyTOTP(ctx, &userpublicapi.VerifyTOTPRequest{
+		UserID: userID.String(),
+		Code:   code,
+	})
+	return err
+}
+
+func (c *client) RemoveTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	_, err := c.api.RemoveTOTP(ctx, &userpublicapi.RemoveTOTPRequest{
+		UserID: userID.String(),
+		Code:   code,
+	})
+	return err
+}
+
+func (c *client) LinkIdentity(ctx context.Context, userID uuid.UUID, issuer, subject string) error {
+	_, err := c.api.LinkIdentity(ctx, &userpublicapi.LinkIdentityRequest{
+		UserID:  userID.String(),
+		Issuer:  issuer,
+		Subject: subject,
+	})
+	return err
+}
+
+func (c *client) UnlinkIdentity(ctx context.Context, userID uuid.UUID, issuer, subject string) error {
+	_, err := c.api.UnlinkIdentity(ctx, &userpublicapi.UnlinkIdentityRequest{
+		UserID:  userID.String(),
+		Issuer:  issuer,
+		Subject: subject,
+	})
+	return err
+}
+
+func (c *client) CreateOrganization(ctx context.Context, name string, ownerID uuid.UUID) (uuid.UUID, error) {
+	response, err := c.api.CreateOrganization(ctx, &userpublicapi.CreateOrganizationRequest{
+		Name:    name,
+		OwnerID: ownerID.String(),
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.Parse(response.OrganizationID)
+}
+
+// FindOrganization returns organization with members, timestamps of organization are not filled
+func (c *client) FindOrganization(ctx context.Context, organizationID uuid.UUID) (model.Organization, error) {
+	response, err := c.api.FindOrganization(ctx, &userpublicapi.FindOrganizationRequest{
+		OrganizationID: organizationID.String(),
+	})
+	if err != nil {
+		return model.Organization{}, err
+	}
+	organization := model.Organization{
+		OrganizationID: organizationID,
+		Name:           response.Name,
+		Members:        make([]model.Member, 0, len(response.Members)),
+	}
+	for _, member := range response.Members {
+		userID, err := uuid.Parse(member.UserID)
+		if err != nil {
+			return model.Organization{}, err
+		}
+		organization.Members = append(organization.Members, model.Member{
+			UserID:      userID,
+			Role:        model.MembershipRole(member.Role),
+			DisplayName: member.DisplayName,
+			JoinedAt:    time.Unix(member.JoinedAt, 0),
+		})
+	}
+	return organization, nil
+}
+
+func (c *client) StoreMember(ctx context.Context, organizationID uuid.UUID, member model.Member) error {
+	_, err := c.api.StoreMember(ctx, &userpublicapi.StoreMemberRequest{
+		OrganizationID: organizationID.String(),
+		UserID:         member.UserID.String(),
+		Role:           userpublicapi.MembershipRole(member.Role), // nolint:gosec
+		DisplayName:    member.DisplayName,
+	})
+	return err
+}
+
+func (c *client) RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error {
+	_, err := c.api.RemoveMember(ctx, &userpublicapi.RemoveMemberRequest{
+		OrganizationID: organizationID.String(),
+		UserID:         userID.String(),
+	})
+	return err
+}
+
+func (c *client) Close() error {
+	return c.conn.Close()
+}
+
+func fromFindUserResponse(response *userpublicapi.FindUserResponse) (User, error) {
+	userID, err := uuid.Parse(response.UserID)
+	if err != nil {
+		return User{}, err
+	}
+	user := User{
+		UserID:       userID,
+		Status:       fromAPIStatus(response.Status),
+		StatusSource: model.StatusSource(response.StatusSource),
+		Login:        response.Login,
+		Profile: model.Profile{
+			DisplayName: response.DisplayName,
+			Locale:      response.Locale,
+			Timezone:    response.Timezone,
+			AvatarURL:   response.AvatarURL,
+		},
+		Contacts:   make([]model.Contact, 0, len(response.Contacts)),
+		Attributes: response.Attributes,
+		Labels:     response.Labels,
+		Roles:      response.Roles,
+	}
+	if response.StatusExpiresAt != nil {
+		statusExpiresAt := time.Unix(*response.StatusExpiresAt, 0)
+		user.StatusExpiresAt = &statusExpiresAt
+	}
+	if response.MergedInto != nil {
+		mergedInto, err := uuid.Parse(*response.MergedInto)
+		if err != nil {
+			return User{}, err
+		}
+		user.MergedInto = &mergedInto
+	}
+	for _, membership := range response.Memberships {
+		organizationID, err := uuid.Parse(membership.OrganizationID)
+		if err != nil {
+			return User{}, err
+		}
+		user.Memberships = append(user.Memberships, Membership{
+			OrganizationID: organizationID,
+			Role:           model.MembershipRole(membership.Role),
+			DisplayName:    membership.DisplayName,
+		})
+	}
+	for _, contact := range response.Contacts {
+		c := model.Contact{
+			Type:    model.ContactType(contact.Type),
+			Value:   contact.Value,
+			Primary: contact.Primary,
+		}
+		if contact.VerifiedAt != nil {
+			verifiedAt := time.Unix(*contact.VerifiedAt, 0)
+			c.VerifiedAt = &verifiedAt
+		}
+		user.Contacts = append(user.Contacts, c)
+	}
+	for _, preference := range response.NotificationPreferences {
+		user.NotificationPreferences = append(user.NotificationPreferences, model.NotificationPreference{
+			Channel:  model.ContactType(preference.Channel),
+			Category: preference.Category,
+			Enabled:  preference.Enabled,
+		})
+	}
+	return user, nil
+}
+
+func fromAPIStatus(status userpublicapi.UserStatus) model.UserStatus {
+	switch status {
+	case userpublicapi.UserStatus_Active:
+		return model.Active
+	case userpublicapi.UserStatus_Deleted:
+		return model.Deleted
+	case userpublicapi.UserStatus_Pending:
+		return model.Pending
+	case userpublicapi.UserStatus_Suspended:
+		return model.Suspended
+	default:
+		return model.Blocked
+	}
+}
+
+func fromUnix(unix *int64) *time.Time {
+	if unix == nil {
+		return nil
+	}
+	t := time.Unix(*unix, 0)
+	return &t
+}
@@ -0,0 +1,670 @@
+package client
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"userservice/pkg/user/domain/model"
+)
+
+// FakeVerificationCode is the only code accepted by FakeClient.VerifyContact and the only totp code accepted by FakeClient
+const FakeVerificationCode = "000000"
+
+var _ UserClient = (*FakeClient)(nil)
+
+// NewFakeClient returns in-memory UserClient for consumers unit tests.
+// It follows the service rules: unique login and contacts, user becomes active once any contact is verified.
+// Attributes are not checked against schema as it is configured on the service side,
+// passwords are kept as is and credentials are never locked, api tokens are not bound to user status
+func NewFakeClient(users ...User) *FakeClient {
+	c := &FakeClient{
+		users:         make(map[uuid.UUID]User, len(users)),
+		roles:         make(map[string][]string),
+		organizations: make(map[uuid.UUID]model.Organization),
+		passwords:     make(map[uuid.UUID]string),
+		apiTokens:     make(map[string]fakeAPIToken),
+		totp:          make(map[uuid.UUID]*fakeTOTP),
+		identities:    make(map[model.IdentitySpec]uuid.UUID),
+	}
+	for _, user := range users {
+		c.users[user.UserID] = user
+	}
+	return c
+}
+
+type FakeClient struct {
+	mu    sync.Mutex
+	users map[uuid.UUID]User
+	// roles maps role name to its permissions
+	roles         map[string][]string
+	organizations map[uuid.UUID]model.Organization
+	passwords     map[uuid.UUID]string
+	// apiTokens maps token to its description
+	apiTokens map[string]fakeAPIToken
+	totp      map[uuid.UUID]*fakeTOTP
+	// identities maps linked identity to its user
+	identities map[model.IdentitySpec]uuid.UUID
+}
+
+type fakeTOTP struct {
+	confirmed     bool
+	recoveryCodes []string
+}
+
+type fakeAPIToken struct {
+	APIToken
+	UserID uuid.UUID
+}
+
+// SetRole defines role permissions used by CheckPermission, roles are assigned through User.Roles
+func (c *FakeClient) SetRole(name string, permissions ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.roles[name] = permissions
+}
+
+func (c *FakeClient) StoreUser(_ context.Context, user User) (uuid.UUID, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored, exists := c.users[user.UserID]
+	if user.UserID != uuid.Nil && !exists {
+		return uuid.Nil, model.ErrUserNotFound
+	}
+	if !exists {
+		for _, u := range c.users {
+			if model.CanonicalLogin(u.Login) == model.CanonicalLogin(user.Login) {
+				return uuid.Nil, model.ErrUserLoginAlreadyUsed
+			}
+		}
+		userID, err := uuid.NewV7()
+		if err != nil {
+			return uuid.Nil, err
+		}
+		stored = User{
+			UserID: userID,
+			Status: model.Pending,
+			Login:  user.Login,
+		}
+	}
+
+	contacts := make([]model.Contact, 0, len(user.Contacts))
+	for _, userContact := range user.Contacts {
+		contact, err := model.NewContact(userContact.Type, userContact.Value, userContact.Primary, model.EmailDomainCaseInsensitive)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if storedContact := findFakeContact(stored.Contacts, contact.Type, contact.Canonical); storedContact != nil {
+			contact.VerifiedAt = storedContact.VerifiedAt
+		}
+		contacts = append(contacts, contact)
+	}
+	for _, u := range c.users {
+		if u.UserID == stored.UserID {
+			continue
+		}
+		for _, contact := range contacts {
+			if findFakeContact(u.Contacts, contact.Type, contact.Canonical) != nil {
+				return uuid.Nil, model.ErrUserContactAlreadyUsed
+			}
+		}
+	}
+
+	profile, err := model.NewProfile(user.Profile.DisplayName, user.Profile.Locale, user.Profile.Timezone, user.Profile.AvatarURL)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	stored.Profile = profile
+	stored.Contacts = contacts
+	c.users[stored.UserID] = withFakeStatus(stored)
+	return stored.UserID, nil
+}
+
+func (c *FakeClient) FindUser(_ context.Context, userID uuid.UUID) (User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.findUser(userID)
+}
+
+func (c *FakeClient) FindUserByIdentity(_ context.Context, issuer, subject string) (User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	identity, err := model.NewIdentitySpec(issuer, subject)
+	if err != nil {
+		return User{}, err
+	}
+	userID, ok := c.identities[identity]
+	if !ok || c.users[userID].Status == model.Deleted {
+		return User{}, model.ErrUserNotFound
+	}
+	return c.findUser(userID)
+}
+
+// findUser returns user with memberships, mu must be held by caller
+func (c *FakeClient) findUser(userID uuid.UUID) (User, error) {
+	user, ok := c.users[userID]
+	if !ok {
+		return User{}, model.ErrUserNotFound
+	}
+	user.Memberships = nil
+	for _, organization := range c.organizations {
+		if i := findFakeMember(organization.Members, userID); i != -1 {
+			user.Memberships = append(user.Memberships, Membership{
+				OrganizationID: organization.OrganizationID,
+				Role:           organization.Members[i].Role,
+				DisplayName:    organization.Members[i].DisplayName,
+			})
+		}
+	}
+	return user, nil
+}
+
+func (c *FakeClient) VerifyContact(_ context.Context, userID uuid.UUID, spec model.ContactSpec, code string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	user, contact, err := c.findUnverifiedContact(userID, spec)
+	if err != nil {
+		return err
+	}
+	if code != FakeVerificationCode {
+		return model.ErrInvalidContactVerificationCode
+	}
+
+	verifiedAt := time.Now()
+	contact.VerifiedAt = &verifiedAt
+	c.users[userID] = withFakeStatus(user)
+	return nil
+}
+
+func (c *FakeClient) ResendVerification(_ context.Context, userID uuid.UUID, spec model.ContactSpec) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, _, err := c.findUnverifiedContact(userID, spec)
+	return err
+}
+
+func (c *FakeClient) SetUserAttributes(_ context.Context, userID uuid.UUID, attributes map[string]string, labels []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	user, ok := c.users[userID]
+	if !ok {
+		return model.ErrUserNotFound
+	}
+
+	userLabels := make([]string, 0, len(labels))
+	for _, l := range labels {
+		label, err := model.NewLabel(l)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(userLabels, label) {
+			userLabels = append(userLabels, label)
+		}
+	}
+	slices.Sort(userLabels)
+
+	user.Attributes = maps.Clone(attributes)
+	user.Labels = userLabels
+	c.users[userID] = user
+	return nil
+}
+
+func (c *FakeClient) SetNotificationPreferences(_ context.Context, userID uuid.UUID, preferences []model.NotificationPreference) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	user, ok := c.users[userID]
+	if !ok {
+		return model.ErrUserNotFound
+	}
+
+	userPreferences := make([]model.NotificationPreference, 0, len(preferences))
+	for _, p := range preferences {
+		preference, err := model.NewNotificationPreference(p.Channel, p.Category, p.Enabled)
+		if err != nil {
+			return err
+		}
+		if slices.ContainsFunc(userPreferences, func(up model.NotificationPreference) bool {
+			return up.Channel == preference.Channel && up.Category == preference.Category
+		}) {
+			return model.ErrInvalidNotificationPreference
+		}
+		userPreferences = append(userPreferences, preference)
+	}
+	slices.SortFunc(userPreferences, func(a, b model.NotificationPreference) int {
+		if a.Channel != b.Channel {
+			return int(a.Channel) - int(b.Channel)
+		}
+		return strings.Compare(a.Category, b.Category)
+	})
+
+	user.NotificationPreferences = userPreferences
+	c.users[userID] = user
+	return nil
+}
+
+func (c *FakeClient) CheckPermission(_ context.Context, userID uuid.UUID, permission string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	user, ok := c.users[userID]
+	if !ok {
+		return false, model.ErrUserNotFound
+	}
+	if user.Status != model.Active {
+		return false, nil
+	}
+	for _, role := range user.Roles {
+		if slices.Contains(c.roles[role], permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *FakeClient) SetPassword(_ context.Context, userID uuid.UUID, password string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	user, ok := c.users[userID]
+	if !ok || user.Status == model.Deleted {
+		return model.ErrUserNotFound
+	}
+	password, err := model.NewPassword(password)
+	if err != nil {
+		return err
+	}
+	c.passwords[userID] = password
+	return nil
+}
+
+func (c *FakeClient) VerifyPassword(_ context.Context, login, password string) (uuid.UUID, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, user := range c.users {
+		if model.CanonicalLogin(user.Login) != model.CanonicalLogin(login) {
+			continue
+		}
+		if p, ok := c.passwords[user.UserID]; ok && p == password && user.Status != model.Deleted {
+			return user.UserID, nil
+		}
+		break
+	}
+	return uuid.Nil, model.ErrInvalidCredentials
+}
+
+func (c *FakeClient) CreateAPIToken(_ context.Context, userID uuid.UUID, name string, scopes []string, expiresAt *time.Time) (uuid.UUID, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	user, ok := c.users[userID]
+	if !ok || user.Status == model.Deleted {
+		return uuid.Nil, "", model.ErrUserNotFound
+	}
+	name, err := model.NewAPITokenName(name)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	scopes, err = model.NewAPITokenScopes(scopes)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	currentTime := time.Now()
+	if expiresAt != nil && !expiresAt.After(currentTime) {
+		return uuid.Nil, "", model.ErrInvalidAPITokenExpiration
+	}
+	for _, token := range c.apiTokens {
+		if token.UserID == userID && strings.EqualFold(token.Name, name) {
+			return uuid.Nil, "", model.ErrAPITokenNameAlreadyUsed
+		}
+	}
+
+	tokenID, err := uuid.NewV7()
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	token := "fake_" + tokenID.String()
+	c.apiTokens[token] = fakeAPIToken{
+		APIToken: APIToken{
+			TokenID:   tokenID,
+			Name:      name,
+			Scopes:    scopes,
+			ExpiresAt: expiresAt,
+			CreatedAt: currentTime,
+		},
+		UserID: userID,
+	}
+	return tokenID, token, nil
+}
+
+func (c *FakeClient) ListAPITokens(_ context.Context, userID uuid.UUID) ([]APIToken, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var tokens []APIToken
+	for _, token := range c.apiTokens {
+		if token.UserID == userID {
+			tokens = append(tokens, token.APIToken)
+		}
+	}
+	slices.SortFunc(tokens, func(a, b APIToken) int { return strings.Compare(a.TokenID.String(), b.TokenID.String()) })
+	return tokens, nil
+}
+
+func (c *FakeClient) RevokeAPIToken(_ context.Context, userID, tokenID uuid.UUID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, token := range c.apiTokens {
+		if token.UserID == userID && token.TokenID == tokenID {
+			delete(c.apiTokens, key)
+			return nil
+		}
+	}
+	return model.ErrAPITokenNotFound
+}
+
+func (c *FakeClient) ValidateToken(_ context.Context, token string) (uuid.UUID, []string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	apiToken, ok := c.apiTokens[token]
+	currentTime := time.Now()
+	if !ok || (apiToken.ExpiresAt != nil && !currentTime.Before(*apiToken.ExpiresAt)) {
+		return uuid.Nil, nil, model.ErrInvalidAPIToken
+	}
+	apiToken.LastUsedAt = &currentTime
+	c.apiTokens[token] = apiToken
+	return apiToken.UserID, apiToken.Scopes, nil
+}
+
+func (c *FakeClient) EnrollTOTP(_ context.Context, userID uuid.UUID) (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	user, ok := c.users[userID]
+	if !ok || user.Status == model.Deleted {
+		return "", "", model.ErrUserNotFound
+	}
+	if factor, ok := c.totp[userID]; ok && factor.confirmed {
+		return "", "", model.ErrTOTPAlreadyEnrolled
+	}
+	c.totp[userID] = &fakeTOTP{}
+	secret := "FAKESECRET"
+	return secret, "otpauth://totp/fake:" + user.Login + "?secret=" + secret, nil
+}
+
+func (c *FakeClient) ConfirmTOTP(_ context.Context, userID uuid.UUID, code string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	factor, ok := c.totp[userID]
+	if !ok {
+		return nil, model.ErrTOTPNotEnrolled
+	}
+	if factor.confirmed {
+		return nil, model.ErrTOTPAlreadyEnrolled
+	}
+	if code != FakeVerificationCode {
+		return nil, model.ErrInvalidTOTPCode
+	}
+	factor.confirmed = true
+	factor.recoveryCodes = []string{"fake-recovery-1", "fake-recovery-2"}
+	return slices.Clone(factor.recoveryCodes), nil
+}
+
+func (c *FakeClient) VerifyTOTP(_ context.Context, userID uuid.UUID, code string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	factor, ok := c.totp[userID]
+	if !ok || !factor.confirmed {
+		return model.ErrTOTPNotEnrolled
+	}
+	if code == FakeVerificationCode {
+		return nil
+	}
+	i := slices.Index(factor.recoveryCodes, code)
+	if i == -1 {
+		return model.ErrInvalidTOTPCode
+	}
+	factor.recoveryCodes = slices.Delete(factor.recoveryCodes, i, i+1)
+	return nil
+}
+
+func (c *FakeClient) RemoveTOTP(_ context.Context, userID uuid.UUID, code string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	factor, ok := c.totp[userID]
+	if !ok {
+		return model.ErrTOTPNotEnrolled
+	}
+	if factor.confirmed && code != FakeVerificationCode && !slices.Contains(factor.recoveryCodes, code) {
+		return model.ErrInvalidTOTPCode
+	}
+	delete(c.totp, userID)
+	return nil
+}
+
+func (c *FakeClient) LinkIdentity(_ context.Context, userID uuid.UUID, issuer, subject string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	identity, err := model.NewIdentitySpec(issuer, subject)
+	if err != nil {
+		return err
+	}
+	user, ok := c.users[userID]
+	if !ok || user.Status == model.Deleted {
+		return model.ErrUserNotFound
+	}
+	if linkedUserID, ok := c.identities[identity]; ok && linkedUserID != userID {
+		return model.ErrIdentityAlreadyLinked
+	}
+	c.identities[identity] = userID
+	return nil
+}
+
+func (c *FakeClient) UnlinkIdentity(_ context.Context, userID uuid.UUID, issuer, subject string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	identity, err := model.NewIdentitySpec(issuer, subject)
+	if err != nil {
+		return err
+	}
+	if linkedUserID, ok := c.identities[identity]; !ok || linkedUserID != userID {
+		return model.ErrIdentityNotFound
+	}
+	delete(c.identities, identity)
+	return nil
+}
+
+func (c *FakeClient) CreateOrganization(_ context.Context, name string, ownerID uuid.UUID) (uuid.UUID, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name, err := model.NewOrganizationName(name)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if _, ok := c.users[ownerID]; !ok {
+		return uuid.Nil, model.ErrUserNotFound
+	}
+	for _, organization := range c.organizations {
+		if strings.EqualFold(organization.Name, name) {
+			return uuid.Nil, model.ErrOrganizationNameAlreadyUsed
+		}
+	}
+
+	organizationID, err := uuid.NewV7()
+	if err != nil {
+		return uuid.Nil, err
+	}
+	currentTime := time.Now()
+	c.organizations[organizationID] = model.Organization{
+		OrganizationID: organizationID,
+		Name:           name,
+		Members: []model.Member{{
+			UserID:   ownerID,
+			Role:     model.MembershipOwner,
+			JoinedAt: currentTime,
+		}},
+		CreatedAt: currentTime,
+		UpdatedAt: currentTime,
+	}
+	return organizationID, nil
+}
+
+func (c *FakeClient) FindOrganization(_ context.Context, organizationID uuid.UUID) (model.Organization, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	organization, ok := c.organizations[organizationID]
+	if !ok {
+		return model.Organization{}, model.ErrOrganizationNotFound
+	}
+	organization.Members = slices.Clone(organization.Members)
+	return organization, nil
+}
+
+func (c *FakeClient) StoreMember(_ context.Context, organizationID uuid.UUID, member model.Member) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	organization, ok := c.organizations[organizationID]
+	if !ok {
+		return model.ErrOrganizationNotFound
+	}
+	if _, ok = c.users[member.UserID]; !ok {
+		return model.ErrUserNotFound
+	}
+	role, err := model.NewMembershipRole(int(member.Role))
+	if err != nil {
+		return err
+	}
+	displayName, err := model.NewMemberDisplayName(member.DisplayName)
+	if err != nil {
+		return err
+	}
+	for _, m := range organization.Members {
+		if m.UserID != member.UserID && displayName != nil && m.DisplayName != nil && strings.EqualFold(*m.DisplayName, *displayName) {
+			return model.ErrMemberDisplayNameAlreadyUsed
+		}
+	}
+
+	members := slices.Clone(organization.Members)
+	i := findFakeMember(members, member.UserID)
+	if i == -1 {
+		members = append(members, model.Member{
+			UserID:   member.UserID,
+			JoinedAt: time.Now(),
+		})
+		i = len(members) - 1
+	}
+	members[i].Role = role
+	members[i].DisplayName = displayName
+	if !slices.ContainsFunc(members, isFakeOwner) {
+		return model.ErrOrganizationWithoutOwner
+	}
+
+	organization.Members = members
+	c.organizations[organizationID] = organization
+	return nil
+}
+
+func (c *FakeClient) RemoveMember(_ context.Context, organizationID, userID uuid.UUID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	organization, ok := c.organizations[organizationID]
+	if !ok {
+		return model.ErrOrganizationNotFound
+	}
+	i := findFakeMember(organization.Members, userID)
+	if i == -1 {
+		return model.ErrOrganizationMemberNotFound
+	}
+	members := slices.Delete(slices.Clone(organization.Members), i, i+1)
+	if !slices.ContainsFunc(members, isFakeOwner) {
+		return model.ErrOrganizationWithoutOwner
+	}
+
+	organization.Members = members
+	c.organizations[organizationID] = organization
+	return nil
+}
+
+func (c *FakeClient) Close() error {
+	return nil
+}
+
+func (c *FakeClient) findUnverifiedContact(userID uuid.UUID, spec model.ContactSpec) (User, *model.Contact, error) {
+	user, ok := c.users[userID]
+	if !ok {
+		return User{}, nil, model.ErrUserNotFound
+	}
+	specContact, err := model.NewContact(spec.Type, spec.Value, false, model.EmailDomainCaseInsensitive)
+	if err != nil {
+		return User{}, nil, err
+	}
+	contact := findFakeContact(user.Contacts, specContact.Type, specContact.Canonical)
+	if contact == nil {
+		return User{}, nil, model.ErrUserContactNotFound
+	}
+	if contact.Verified() {
+		return User{}, nil, model.ErrUserContactAlreadyVerified
+	}
+	return user, contact, nil
+}
+
+func withFakeStatus(user User) User {
+	if user.StatusSource == model.StatusSourceManual {
+		return user
+	}
+	status := model.Pending
+	for _, contact := range user.Contacts {
+		if contact.Verified() {
+			status = model.Active
+			break
+		}
+	}
+	if user.Status.CanTransitTo(status) {
+		user.Status = status
+	}
+	return user
+}
+
+func findFakeContact(contacts []model.Contact, contactType model.ContactType, canonical string) *model.Contact {
+	for i := range contacts {
+		if contacts[i].Type == contactType && contacts[i].Canonical == canonical {
+			return &contacts[i]
+		}
+	}
+	return nil
+}
+
+func findFakeMember(members []model.Member, userID uuid.UUID) int {
+	return slices.IndexFunc(members, func(m model.Member) bool {
+		return m.UserID == userID
+	})
+}
+
+func isFakeOwner(member model.Member) bool {
+	return member.Role == model.MembershipOwner
+}
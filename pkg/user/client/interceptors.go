@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"userservice/pkg/user/domain/model"
+)
+
+var knownErrors = []struct {
+	err  error
+	code codes.Code
+}{
+	{err: model.ErrUserNotFound, code: codes.NotFound},
+	{err: model.ErrUserLoginAlreadyUsed, code: codes.AlreadyExists},
+	{err: model.ErrUserContactAlreadyUsed, code: codes.AlreadyExists},
+	{err: model.ErrInvalidContact, code: codes.InvalidArgument},
+	{err: model.ErrInvalidPhone, code: codes.InvalidArgument},
+	{err: model.ErrUserContactNotFound, code: codes.NotFound},
+	{err: model.ErrUserContactAlreadyVerified, code: codes.FailedPrecondition},
+	{err: model.ErrContactVerificationNotFound, code: codes.FailedPrecondition},
+	{err: model.ErrContactVerificationExpired, code: codes.FailedPrecondition},
+	{err: model.ErrInvalidContactVerificationCode, code: codes.InvalidArgument},
+	{err: model.ErrContactVerificationResent, code: codes.ResourceExhausted},
+	{err: model.ErrInvalidDisplayName, code: codes.InvalidArgument},
+	{err: model.ErrInvalidLocale, code: codes.InvalidArgument},
+	{err: model.ErrInvalidTimezone, code: codes.InvalidArgument},
+	{err: model.ErrInvalidAvatarURL, code: codes.InvalidArgument},
+	{err: model.ErrUnknownAttribute, code: codes.InvalidArgument},
+	{err: model.ErrInvalidAttributeValue, code: codes.InvalidArgument},
+	{err: model.ErrInvalidLabel, code: codes.InvalidArgument},
+	{err: model.ErrInvalidNotificationPreference, code: codes.InvalidArgument},
+	{err: model.ErrInvalidTenant, code: codes.InvalidArgument},
+	{err: model.ErrRoleNotFound, code: codes.NotFound},
+	{err: model.ErrInvalidRole, code: codes.InvalidArgument},
+	{err: model.ErrInvalidPermission, code: codes.InvalidArgument},
+	{err: model.ErrOrganizationNotFound, code: codes.NotFound},
+	{err: model.ErrOrganizationNameAlreadyUsed, code: codes.AlreadyExists},
+	{err: model.ErrInvalidOrganizationName, code: codes.InvalidArgument},
+	{err: model.ErrInvalidMembershipRole, code: codes.InvalidArgument},
+	{err: model.ErrOrganizationMemberNotFound, code: codes.NotFound},
+	{err: model.ErrMemberDisplayNameAlreadyUsed, code: codes.AlreadyExists},
+	{err: model.ErrOrganizationWithoutOwner, code: codes.FailedPrecondition},
+	{err: model.ErrUserNotDeleted, code: codes.FailedPrecondition},
+	{err: model.ErrInvalidUserStatus, code: codes.InvalidArgument},
+	{err: model.ErrInvalidUserStatusTransition, code: codes.FailedPrecondition},
+	{err: model.ErrInvalidStatusExpiration, code: codes.InvalidArgument},
+	{err: model.ErrUserStatusOverridden, code: codes.FailedPrecondition},
+	{err: model.ErrMergeSameUser, code: codes.InvalidArgument},
+	{err: model.ErrMergeDeletedUser, code: codes.FailedPrecondition},
+	{err: model.ErrUserMerged, code: codes.FailedPrecondition},
+	{err: model.ErrInvalidPassword, code: codes.InvalidArgument},
+	{err: model.ErrInvalidCredentials, code: codes.Unauthenticated},
+	{err: model.ErrCredentialsLocked, code: codes.PermissionDenied},
+	{err: model.ErrAPITokenNotFound, code: codes.NotFound},
+	{err: model.ErrAPITokenNameAlreadyUsed, code: codes.AlreadyExists},
+	{err: model.ErrInvalidAPITokenName, code: codes.InvalidArgument},
+	{err: model.ErrInvalidAPITokenExpiration, code: codes.InvalidArgument},
+	{err: model.ErrInvalidAPIToken, code: codes.Unauthenticated},
+	{err: model.ErrTOTPAlreadyEnrolled, code: codes.FailedPrecondition},
+	{err: model.ErrTOTPNotEnrolled, code: codes.FailedPrecondition},
+	{err: model.ErrInvalidTOTPCode, code: codes.Unauthenticated},
+	{err: model.ErrTOTPNotConfigured, code: codes.Unimplemented},
+	{err: model.ErrInvalidIdentity, code: codes.InvalidArgument},
+	{err: model.ErrIdentityAlreadyLinked, code: codes.AlreadyExists},
+	{err: model.ErrIdentityNotFound, code: codes.NotFound},
+}
+
+// newErrorsInterceptor translates statuses sent by the service back into domain errors
+func newErrorsInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err == nil {
+			return nil
+		}
+		s, ok := status.FromError(err)
+		if !ok {
+			return err
+		}
+		for _, e := range knownErrors {
+			if s.Code() == e.code && s.Message() == e.err.Error() {
+				return e.err
+			}
+		}
+		// unknown statuses are returned as is, so errors of other entities are not mistaken for missing user
+		return err
+	}
+}
+
+// newTenantInterceptor passes tenant of client in metadata of outgoing requests
+func newTenantInterceptor(tenantID string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if tenantID != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-tenant-id", tenantID)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// retryableMethods do not change state of service, so request may be repeated even when it reached service.
+// ValidateToken records usage of token and verification methods count failed attempts, so they are not retried
+var retryableMethods = map[string]struct{}{
+	"/User.UserPublicAPI/FindUser":           {},
+	"/User.UserPublicAPI/FindUserByIdentity": {},
+	"/User.UserPublicAPI/CheckPermission":    {},
+	"/User.UserPublicAPI/ListAPITokens":      {},
+	"/User.UserPublicAPI/FindOrganization":   {},
+}
+
+// newRetryInterceptor retries read-only methods while service is unavailable
+func newRetryInterceptor(maxRetries int, initialBackoff, maxBackoff time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := retryableMethods[method]; !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		backoff := initialBackoff
+		for attempt := 0; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if status.Code(err) != codes.Unavailable || attempt >= maxRetries {
+				return err
+			}
+
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+	}
+}
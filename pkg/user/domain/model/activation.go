@@ -0,0 +1,44 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ActivationPolicy decides whether user with given contacts is active or pending
+type ActivationPolicy interface {
+	UserStatus(contacts []Contact) UserStatus
+}
+
+// ParseActivationPolicy returns policy by name:
+// verified_contact - any verified contact, any_contact - any contact, email - any email contact, always - always active
+func ParseActivationPolicy(name string) (ActivationPolicy, error) {
+	switch name {
+	case "verified_contact":
+		return contactActivationPolicy(Contact.Verified), nil
+	case "any_contact":
+		return contactActivationPolicy(func(Contact) bool { return true }), nil
+	case "email":
+		return contactActivationPolicy(func(c Contact) bool { return c.Type == ContactEmail }), nil
+	case "always":
+		return alwaysActivationPolicy{}, nil
+	default:
+		return nil, fmt.Errorf("unknown activation policy %q", name)
+	}
+}
+
+// contactActivationPolicy activates user having contact matching the predicate
+type contactActivationPolicy func(contact Contact) bool
+
+func (p contactActivationPolicy) UserStatus(contacts []Contact) UserStatus {
+	if slices.ContainsFunc(contacts, p) {
+		return Active
+	}
+	return Pending
+}
+
+type alwaysActivationPolicy struct{}
+
+func (alwaysActivationPolicy) UserStatus([]Contact) UserStatus {
+	return Active
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseActivationPolicy(t *testing.T) {
+	verifiedAt := time.Unix(1700000000, 0)
+	unverifiedEmail := Contact{Type: ContactEmail, Value: "user@example.com"}
+	verifiedEmail := Contact{Type: ContactEmail, Value: "user@example.com", VerifiedAt: &verifiedAt}
+	unverifiedPhone := Contact{Type: ContactPhone, Value: "+79991234567"}
+
+	tests := []struct {
+		name     string
+		policy   string
+		contacts []Contact
+		want     UserStatus
+		err      bool
+	}{
+		{name: "verified contact without contacts", policy: "verified_contact", want: Pending},
+		{name: "verified contact with unverified contact", policy: "verified_contact", contacts: []Contact{unverifiedEmail}, want: Pending},
+		{name: "verified contact with verified contact", policy: "verified_contact", contacts: []Contact{unverifiedPhone, verifiedEmail}, want: Active},
+		{name: "any contact without contacts", policy: "any_contact", want: Pending},
+		{name: "any contact with unverified contact", policy: "any_contact", contacts: []Contact{unverifiedPhone}, want: Active},
+		{name: "email with phone", policy: "email", contacts: []Contact{unverifiedPhone}, want: Pending},
+		{name: "email with unverified email", policy: "email", contacts: []Contact{unverifiedEmail}, want: Active},
+		{name: "always without contacts", policy: "always", want: Active},
+		{name: "unknown", policy: "verified", err: true},
+		{name: "empty", policy: "", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ParseActivationPolicy(tt.policy)
+			if (err != nil) != tt.err {
+				t.Fatalf("ParseActivationPolicy(%q) error = %v, want error %v", tt.policy, err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if got := policy.UserStatus(tt.contacts); got != tt.want {
+				t.Errorf("UserStatus() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
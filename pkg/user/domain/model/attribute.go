@@ -0,0 +1,104 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrUnknownAttribute      = errors.New("unknown attribute")
+	ErrInvalidAttributeValue = errors.New("invalid attribute value")
+	ErrInvalidLabel          = errors.New("invalid label")
+)
+
+type AttributeType int
+
+const (
+	AttributeString AttributeType = iota
+	AttributeInt
+	AttributeBool
+)
+
+// Attribute value is kept in canonical string form of its type
+type Attribute struct {
+	Key   string
+	Type  AttributeType
+	Value string
+}
+
+// AttributeSchema declares attribute keys allowed to be set on users and their types
+type AttributeSchema map[string]AttributeType
+
+const maxAttributeStringLength = 255
+
+var (
+	attributeKeyRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+	labelRegexp        = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]{0,63}$`)
+)
+
+// ParseAttributeSchema builds schema from attribute key to type name pairs, supported types are string, int and bool
+func ParseAttributeSchema(schema map[string]string) (AttributeSchema, error) {
+	result := make(AttributeSchema, len(schema))
+	for key, typeName := range schema {
+		if !attributeKeyRegexp.MatchString(key) {
+			return nil, fmt.Errorf("invalid attribute key %q", key)
+		}
+		switch typeName {
+		case "string":
+			result[key] = AttributeString
+		case "int":
+			result[key] = AttributeInt
+		case "bool":
+			result[key] = AttributeBool
+		default:
+			return nil, fmt.Errorf("unknown attribute type %q of %q", typeName, key)
+		}
+	}
+	return result, nil
+}
+
+// NewAttribute checks attribute against schema and brings value to canonical form of attribute type
+func (s AttributeSchema) NewAttribute(key, value string) (Attribute, error) {
+	attributeType, ok := s[key]
+	if !ok {
+		return Attribute{}, ErrUnknownAttribute
+	}
+
+	switch attributeType {
+	case AttributeInt:
+		v, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return Attribute{}, ErrInvalidAttributeValue
+		}
+		value = strconv.FormatInt(v, 10)
+	case AttributeBool:
+		v, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return Attribute{}, ErrInvalidAttributeValue
+		}
+		value = strconv.FormatBool(v)
+	default:
+		if utf8.RuneCountInString(value) > maxAttributeStringLength {
+			return Attribute{}, ErrInvalidAttributeValue
+		}
+	}
+
+	return Attribute{
+		Key:   key,
+		Type:  attributeType,
+		Value: value,
+	}, nil
+}
+
+// NewLabel validates label, labels are case-insensitive and stored in lower case
+func NewLabel(label string) (string, error) {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if !labelRegexp.MatchString(label) {
+		return "", ErrInvalidLabel
+	}
+	return label, nil
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUserContactAlreadyUsed         = errors.New("user contact already used")
+	ErrInvalidContact                 = errors.New("invalid contact")
+	ErrInvalidPhone                   = errors.New("invalid phone number")
+	ErrUserContactNotFound            = errors.New("user contact not found")
+	ErrUserContactAlreadyVerified     = errors.New("user contact already verified")
+	ErrContactVerificationNotFound    = errors.New("contact verification not found")
+	ErrContactVerificationExpired     = errors.New("contact verification expired")
+	ErrInvalidContactVerificationCode = errors.New("invalid contact verification code")
+	ErrContactVerificationResent      = errors.New("contact verification code was sent recently")
+)
+
+type ContactType int
+
+const (
+	ContactEmail ContactType = iota
+	ContactTelegram
+	ContactPhone
+)
+
+// Contact keeps Value in the form user entered it, Canonical is used for uniqueness and lookups
+type Contact struct {
+	Type       ContactType
+	Value      string
+	Canonical  string
+	Primary    bool
+	VerifiedAt *time.Time
+}
+
+func (c Contact) Verified() bool {
+	return c.VerifiedAt != nil
+}
+
+// ContactVerification is a pending one-time code check of unverified contact, only code hash is stored.
+// Value is canonical form of contact
+type ContactVerification struct {
+	Type      ContactType
+	Value     string
+	CodeHash  string
+	ExpiresAt time.Time
+	// FailedAttempts counts wrong codes, verification is dropped when too many codes are wrong
+	FailedAttempts int
+}
+
+// ContactSpec identifies contact by canonical form of its value
+type ContactSpec struct {
+	Type  ContactType
+	Value string
+}
+
+// NewContact validates contact and brings its value to the stored form, phones are normalized to E.164.
+// Emails are made canonical according to emailNormalization, telegram handles lose leading @ and case
+func NewContact(contactType ContactType, value string, primary bool, emailNormalization EmailNormalization) (Contact, error) {
+	value = strings.TrimSpace(value)
+	switch contactType {
+	case ContactEmail, ContactTelegram:
+		if value == "" {
+			return Contact{}, ErrInvalidContact
+		}
+	case ContactPhone:
+		phone, err := normalizePhone(value)
+		if err != nil {
+			return Contact{}, err
+		}
+		value = phone
+	default:
+		return Contact{}, ErrInvalidContact
+	}
+	canonical := canonicalContactValue(contactType, value, emailNormalization)
+	if canonical == "" {
+		return Contact{}, ErrInvalidContact
+	}
+	return Contact{
+		Type:      contactType,
+		Value:     value,
+		Canonical: canonical,
+		Primary:   primary,
+	}, nil
+}
+
+// E.164 allows up to 15 digits, shortest numbers in use have 8 digits with country code
+const (
+	minPhoneDigits = 8
+	maxPhoneDigits = 15
+)
+
+func normalizePhone(phone string) (string, error) {
+	var digits strings.Builder
+	for i, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+	if !strings.HasPrefix(phone, "+") {
+		return "", ErrInvalidPhone
+	}
+	d := digits.String()
+	if len(d) < minPhoneDigits || len(d) > maxPhoneDigits || d[0] == '0' {
+		return "", ErrInvalidPhone
+	}
+	return "+" + d, nil
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidPassword     = errors.New("invalid password")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrCredentialsLocked   = errors.New("credentials are locked")
+	ErrInvalidLockoutLimit = errors.New("invalid password lockout limit")
+)
+
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 128
+)
+
+// Credentials holds password of user, PasswordHash is encoded together with hashing algorithm and its parameters
+type Credentials struct {
+	PasswordHash string
+	// FailedAttempts counts failed verifications in a row, it is reset on successful verification and on lockout
+	FailedAttempts    int
+	LockedUntil       *time.Time
+	PasswordChangedAt time.Time
+}
+
+func (c Credentials) Locked(currentTime time.Time) bool {
+	return c.LockedUntil != nil && currentTime.Before(*c.LockedUntil)
+}
+
+// PasswordLockout locks credentials for Duration after MaxFailedAttempts failed verifications in a row
+type PasswordLockout struct {
+	MaxFailedAttempts int
+	Duration          time.Duration
+}
+
+func NewPasswordLockout(maxFailedAttempts int, duration time.Duration) (PasswordLockout, error) {
+	if maxFailedAttempts <= 0 || duration <= 0 {
+		return PasswordLockout{}, ErrInvalidLockoutLimit
+	}
+	return PasswordLockout{
+		MaxFailedAttempts: maxFailedAttempts,
+		Duration:          duration,
+	}, nil
+}
+
+// NewPassword checks password length, password is used as is without trimming
+func NewPassword(password string) (string, error) {
+	length := utf8.RuneCountInString(password)
+	if length < minPasswordLength || length > maxPasswordLength {
+		return "", ErrInvalidPassword
+	}
+	return password, nil
+}
@@ -8,10 +8,12 @@ import (
 
 type UserCreated struct {
 	UserID    uuid.UUID
+	TenantID  string
 	Status    UserStatus
 	Login     string
-	Email     *string
-	Telegram  *string
+	Profile   Profile
+	Contacts  []Contact
+	Version   int64
 	CreatedAt time.Time
 }
 
@@ -21,29 +23,269 @@ func (u UserCreated) Type() string {
 
 type UserUpdated struct {
 	UserID        uuid.UUID
-	UpdatedFields *struct {
-		Status   *UserStatus
-		Email    *string
-		Telegram *string
-	}
-	RemovedFields *struct {
-		Email    *bool
-		Telegram *bool
-	}
-	UpdatedAt time.Time
+	TenantID      string
+	UpdatedFields *UpdatedFields
+	RemovedFields *RemovedFields
+	// PreviousFields holds values updated and removed fields had before the change, unset field had no value
+	PreviousFields *UpdatedFields
+	Version        int64
+	UpdatedAt      time.Time
+}
+
+type UpdatedFields struct {
+	Status       *UserStatus
+	StatusSource *StatusSource
+	// StatusExpiresAt is set when status becomes temporary or its expiration is changed
+	StatusExpiresAt *time.Time
+	// Contacts added to user or changed primary flag
+	Contacts    []Contact
+	DisplayName *string
+	Locale      *string
+	Timezone    *string
+	AvatarURL   *string
+	// Attributes added to user or changed value
+	Attributes []Attribute
+	// Labels added to user
+	Labels []string
+	// NotificationPreferences added to user or changed enabled flag
+	NotificationPreferences []NotificationPreference
+}
+
+type RemovedFields struct {
+	// StatusExpiresAt is set when temporary status expires or is replaced by status without expiration
+	StatusExpiresAt bool
+	Contacts        []Contact
+	DisplayName     bool
+	Locale          bool
+	Timezone        bool
+	AvatarURL       bool
+	// Attributes keys removed from user
+	Attributes              []string
+	Labels                  []string
+	NotificationPreferences []NotificationPreference
 }
 
 func (u UserUpdated) Type() string {
 	return "user_updated"
 }
 
+// ContactVerificationRequested carries one-time code to be delivered to the contact
+type ContactVerificationRequested struct {
+	UserID    uuid.UUID
+	TenantID  string
+	Contact   Contact
+	Code      string
+	ExpiresAt time.Time
+	Version   int64
+}
+
+func (c ContactVerificationRequested) Type() string {
+	return "contact_verification_requested"
+}
+
 type UserDeleted struct {
 	UserID    uuid.UUID
+	TenantID  string
 	Status    UserStatus
 	DeletedAt time.Time
 	Hard      bool
+	Version   int64
 }
 
 func (u UserDeleted) Type() string {
 	return "user_deleted"
 }
+
+type UserRestored struct {
+	UserID     uuid.UUID
+	TenantID   string
+	Status     UserStatus
+	RestoredAt time.Time
+	Version    int64
+}
+
+func (u UserRestored) Type() string {
+	return "user_restored"
+}
+
+// UserMerged is emitted when source user is deleted after its contacts, attributes, labels, preferences, roles
+// and memberships were moved to target user
+type UserMerged struct {
+	SourceUserID uuid.UUID
+	TargetUserID uuid.UUID
+	TenantID     string
+	MergedAt     time.Time
+	// SourceVersion and TargetVersion are versions users got by merge
+	SourceVersion int64
+	TargetVersion int64
+	// Labels, NotificationPreferences and Roles are values source had, target has all of them after merge
+	// except preferences it already had for the same channel and category
+	Labels                  []string
+	NotificationPreferences []NotificationPreference
+	Roles                   []string
+	// OrganizationIDs are organizations memberships of source were transferred to target in
+	OrganizationIDs []uuid.UUID
+}
+
+func (u UserMerged) Type() string {
+	return "user_merged"
+}
+
+// PasswordChanged is emitted when password of user is set, it never carries password or its hash
+type PasswordChanged struct {
+	UserID    uuid.UUID
+	TenantID  string
+	ChangedAt time.Time
+	Version   int64
+}
+
+func (p PasswordChanged) Type() string {
+	return "password_changed"
+}
+
+// APITokenCreated never carries token or its hash
+type APITokenCreated struct {
+	UserID    uuid.UUID
+	TenantID  string
+	TokenID   uuid.UUID
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+	Version   int64
+}
+
+func (a APITokenCreated) Type() string {
+	return "api_token_created"
+}
+
+type APITokenRevoked struct {
+	UserID    uuid.UUID
+	TenantID  string
+	TokenID   uuid.UUID
+	RevokedAt time.Time
+	Version   int64
+}
+
+func (a APITokenRevoked) Type() string {
+	return "api_token_revoked"
+}
+
+// TOTPEnrolled is emitted when enrollment of totp factor is confirmed with the first code
+type TOTPEnrolled struct {
+	UserID     uuid.UUID
+	TenantID   string
+	EnrolledAt time.Time
+	Version    int64
+}
+
+func (t TOTPEnrolled) Type() string {
+	return "totp_enrolled"
+}
+
+type TOTPRemoved struct {
+	UserID    uuid.UUID
+	TenantID  string
+	RemovedAt time.Time
+	Version   int64
+}
+
+func (t TOTPRemoved) Type() string {
+	return "totp_removed"
+}
+
+type IdentityLinked struct {
+	UserID   uuid.UUID
+	TenantID string
+	Issuer   string
+	Subject  string
+	LinkedAt time.Time
+	Version  int64
+}
+
+func (i IdentityLinked) Type() string {
+	return "identity_linked"
+}
+
+type IdentityUnlinked struct {
+	UserID     uuid.UUID
+	TenantID   string
+	Issuer     string
+	Subject    string
+	UnlinkedAt time.Time
+	Version    int64
+}
+
+func (i IdentityUnlinked) Type() string {
+	return "identity_unlinked"
+}
+
+// RoleUpdated is emitted when role is created or its permissions are changed
+type RoleUpdated struct {
+	Role        string
+	Permissions []string
+	UpdatedAt   time.Time
+}
+
+func (r RoleUpdated) Type() string {
+	return "role_updated"
+}
+
+type UserRoleAssigned struct {
+	UserID     uuid.UUID
+	TenantID   string
+	Role       string
+	AssignedAt time.Time
+	Version    int64
+}
+
+func (u UserRoleAssigned) Type() string {
+	return "user_role_assigned"
+}
+
+type UserRoleRevoked struct {
+	UserID    uuid.UUID
+	TenantID  string
+	Role      string
+	RevokedAt time.Time
+	Version   int64
+}
+
+func (u UserRoleRevoked) Type() string {
+	return "user_role_revoked"
+}
+
+type OrganizationCreated struct {
+	OrganizationID uuid.UUID
+	TenantID       string
+	Name           string
+	OwnerID        uuid.UUID
+	CreatedAt      time.Time
+}
+
+func (o OrganizationCreated) Type() string {
+	return "organization_created"
+}
+
+// OrganizationMemberStored is emitted when user joins organization or membership role or display name is changed
+type OrganizationMemberStored struct {
+	OrganizationID uuid.UUID
+	TenantID       string
+	Member         Member
+	UpdatedAt      time.Time
+}
+
+func (o OrganizationMemberStored) Type() string {
+	return "organization_member_stored"
+}
+
+type OrganizationMemberRemoved struct {
+	OrganizationID uuid.UUID
+	TenantID       string
+	UserID         uuid.UUID
+	RemovedAt      time.Time
+}
+
+func (o OrganizationMemberRemoved) Type() string {
+	return "organization_member_removed"
+}
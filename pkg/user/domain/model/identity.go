@@ -0,0 +1,43 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidIdentity       = errors.New("invalid identity")
+	ErrIdentityAlreadyLinked = errors.New("identity already linked")
+	ErrIdentityNotFound      = errors.New("identity not found")
+)
+
+const maxIdentityPartLength = 255
+
+// IdentitySpec identifies account of external identity provider, issuer and subject are compared exactly as OIDC requires
+type IdentitySpec struct {
+	Issuer  string
+	Subject string
+}
+
+// Identity is account of external identity provider linked to user, pair of issuer and subject is linked to one user at most
+type Identity struct {
+	Issuer   string
+	Subject  string
+	LinkedAt time.Time
+}
+
+// NewIdentitySpec trims issuer and subject, their case is kept
+func NewIdentitySpec(issuer, subject string) (IdentitySpec, error) {
+	issuer = strings.TrimSpace(issuer)
+	subject = strings.TrimSpace(subject)
+	if issuer == "" || subject == "" ||
+		utf8.RuneCountInString(issuer) > maxIdentityPartLength || utf8.RuneCountInString(subject) > maxIdentityPartLength {
+		return IdentitySpec{}, ErrInvalidIdentity
+	}
+	return IdentitySpec{
+		Issuer:  issuer,
+		Subject: subject,
+	}, nil
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EmailNormalization tells which part of email address is compared case-insensitively
+type EmailNormalization int
+
+const (
+	// EmailDomainCaseInsensitive lower-cases domain part only, local part is case-sensitive as RFC 5321 allows
+	EmailDomainCaseInsensitive EmailNormalization = iota
+	// EmailCaseInsensitive lower-cases whole address
+	EmailCaseInsensitive
+)
+
+func ParseEmailNormalization(name string) (EmailNormalization, error) {
+	switch name {
+	case "domain":
+		return EmailDomainCaseInsensitive, nil
+	case "full":
+		return EmailCaseInsensitive, nil
+	default:
+		return 0, fmt.Errorf("unknown email normalization %q", name)
+	}
+}
+
+// CanonicalLogin returns form of login used for uniqueness and lookups
+func CanonicalLogin(login string) string {
+	return strings.ToLower(strings.TrimSpace(login))
+}
+
+// canonicalContactValue returns form of trimmed contact value used for uniqueness and lookups
+func canonicalContactValue(contactType ContactType, value string, emailNormalization EmailNormalization) string {
+	switch contactType {
+	case ContactEmail:
+		if emailNormalization == EmailCaseInsensitive {
+			return strings.ToLower(value)
+		}
+		i := strings.LastIndex(value, "@")
+		return value[:i+1] + strings.ToLower(value[i+1:])
+	case ContactTelegram:
+		return strings.ToLower(strings.TrimPrefix(value, "@"))
+	default:
+		return value
+	}
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanonicalLogin(t *testing.T) {
+	tests := []struct {
+		login string
+		want  string
+	}{
+		{login: "user", want: "user"},
+		{login: "  User.Name ", want: "user.name"},
+		{login: "ПОЛЬЗОВАТЕЛЬ", want: "пользователь"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalLogin(tt.login); got != tt.want {
+			t.Errorf("CanonicalLogin(%q) = %q, want %q", tt.login, got, tt.want)
+		}
+	}
+}
+
+func TestNewContactCanonical(t *testing.T) {
+	tests := []struct {
+		name               string
+		contactType        ContactType
+		value              string
+		emailNormalization EmailNormalization
+		wantValue          string
+		wantCanonical      string
+		err                error
+	}{
+		{name: "email domain", contactType: ContactEmail, value: " User@Example.COM ", wantValue: "User@Example.COM", wantCanonical: "User@example.com"},
+		{name: "email with @ in local part", contactType: ContactEmail, value: `"a@b"@Example.com`, wantValue: `"a@b"@Example.com`, wantCanonical: `"a@b"@example.com`},
+		{name: "email full", contactType: ContactEmail, value: "User@Example.COM", emailNormalization: EmailCaseInsensitive, wantValue: "User@Example.COM", wantCanonical: "user@example.com"},
+		{name: "empty email", contactType: ContactEmail, value: "  ", err: ErrInvalidContact},
+		{name: "telegram", contactType: ContactTelegram, value: "@User_Name", wantValue: "@User_Name", wantCanonical: "user_name"},
+		{name: "telegram of @ only", contactType: ContactTelegram, value: "@", err: ErrInvalidContact},
+		{name: "phone", contactType: ContactPhone, value: "+7 (999) 123-45-67", wantValue: "+79991234567", wantCanonical: "+79991234567"},
+		{name: "phone without plus", contactType: ContactPhone, value: "79991234567", err: ErrInvalidPhone},
+		{name: "short phone", contactType: ContactPhone, value: "+7999123", err: ErrInvalidPhone},
+		{name: "unknown type", contactType: ContactType(100), value: "value", err: ErrInvalidContact},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contact, err := NewContact(tt.contactType, tt.value, false, tt.emailNormalization)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("NewContact() error = %v, want %v", err, tt.err)
+			}
+			if contact.Value != tt.wantValue || contact.Canonical != tt.wantCanonical {
+				t.Errorf("NewContact() = (%q, %q), want (%q, %q)", contact.Value, contact.Canonical, tt.wantValue, tt.wantCanonical)
+			}
+		})
+	}
+}
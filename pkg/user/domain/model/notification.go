@@ -0,0 +1,37 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidNotificationPreference = errors.New("invalid notification preference")
+
+var notificationCategoryRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// NotificationPreference opts user in or out of notifications of category, e.g. security or marketing, sent over channel.
+// Notification services apply their own defaults for channels and categories user has no preference for
+type NotificationPreference struct {
+	Channel  ContactType
+	Category string
+	Enabled  bool
+}
+
+// NewNotificationPreference validates preference, categories are case-insensitive and stored in lower case
+func NewNotificationPreference(channel ContactType, category string, enabled bool) (NotificationPreference, error) {
+	switch channel {
+	case ContactEmail, ContactTelegram, ContactPhone:
+	default:
+		return NotificationPreference{}, ErrInvalidNotificationPreference
+	}
+	category = strings.ToLower(strings.TrimSpace(category))
+	if !notificationCategoryRegexp.MatchString(category) {
+		return NotificationPreference{}, ErrInvalidNotificationPreference
+	}
+	return NotificationPreference{
+		Channel:  channel,
+		Category: category,
+		Enabled:  enabled,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrOrganizationNotFound         = errors.New("organization not found")
+	ErrOrganizationNameAlreadyUsed  = errors.New("organization name already used")
+	ErrInvalidOrganizationName      = errors.New("invalid organization name")
+	ErrInvalidMembershipRole        = errors.New("invalid membership role")
+	ErrOrganizationMemberNotFound   = errors.New("organization member not found")
+	ErrMemberDisplayNameAlreadyUsed = errors.New("member display name already used")
+	ErrOrganizationWithoutOwner     = errors.New("organization must have at least one owner")
+)
+
+type MembershipRole int
+
+const (
+	MembershipMember MembershipRole = iota
+	MembershipOwner
+)
+
+const maxOrganizationNameLength = 255
+
+// Member is user membership in organization, DisplayName is unique within organization regardless of case
+type Member struct {
+	UserID      uuid.UUID
+	Role        MembershipRole
+	DisplayName *string
+	JoinedAt    time.Time
+}
+
+// Organization belongs to tenant, its members are users of the same tenant
+type Organization struct {
+	OrganizationID uuid.UUID
+	TenantID       string
+	Name           string
+	Members        []Member
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// NewOrganizationName trims name, organization names are compared case-insensitively
+func NewOrganizationName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxOrganizationNameLength {
+		return "", ErrInvalidOrganizationName
+	}
+	return name, nil
+}
+
+func NewMembershipRole(role int) (MembershipRole, error) {
+	switch MembershipRole(role) {
+	case MembershipMember, MembershipOwner:
+		return MembershipRole(role), nil
+	default:
+		return 0, ErrInvalidMembershipRole
+	}
+}
+
+// NewMemberDisplayName validates display name of member, empty value is treated as unset
+func NewMemberDisplayName(displayName *string) (*string, error) {
+	v := trimmed(displayName)
+	if v != nil && utf8.RuneCountInString(*v) > maxDisplayNameLength {
+		return nil, ErrInvalidDisplayName
+	}
+	return v, nil
+}
+
+type OrganizationFindSpec struct {
+	TenantID       *string
+	OrganizationID *uuid.UUID
+	Name           *string
+}
+
+type OrganizationRepository interface {
+	NextID() (uuid.UUID, error)
+	Store(organization Organization) error
+	Find(spec OrganizationFindSpec) (*Organization, error)
+	// FindByMember returns organizations user is member of
+	FindByMember(userID uuid.UUID) ([]Organization, error)
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+)
+
+var (
+	ErrInvalidDisplayName = errors.New("invalid display name")
+	ErrInvalidLocale      = errors.New("invalid locale")
+	ErrInvalidTimezone    = errors.New("invalid timezone")
+	ErrInvalidAvatarURL   = errors.New("invalid avatar url")
+)
+
+const (
+	maxDisplayNameLength = 255
+	maxAvatarURLLength   = 2048
+)
+
+// Profile holds optional user presentation settings
+type Profile struct {
+	DisplayName *string
+	// Locale is BCP 47 language tag in canonical form
+	Locale *string
+	// Timezone is IANA time zone name
+	Timezone  *string
+	AvatarURL *string
+}
+
+// NewProfile validates profile fields and brings them to the stored form, empty values are treated as unset
+func NewProfile(displayName, locale, timezone, avatarURL *string) (Profile, error) {
+	var profile Profile
+
+	if v := trimmed(displayName); v != nil {
+		if utf8.RuneCountInString(*v) > maxDisplayNameLength {
+			return Profile{}, ErrInvalidDisplayName
+		}
+		profile.DisplayName = v
+	}
+
+	if v := trimmed(locale); v != nil {
+		tag, err := language.Parse(*v)
+		if err != nil {
+			return Profile{}, ErrInvalidLocale
+		}
+		canonical := tag.String()
+		profile.Locale = &canonical
+	}
+
+	if v := trimmed(timezone); v != nil {
+		if *v == "Local" {
+			return Profile{}, ErrInvalidTimezone
+		}
+		location, err := time.LoadLocation(*v)
+		if err != nil {
+			return Profile{}, ErrInvalidTimezone
+		}
+		name := location.String()
+		profile.Timezone = &name
+	}
+
+	if v := trimmed(avatarURL); v != nil {
+		u, err := url.Parse(*v)
+		if err != nil || len(*v) > maxAvatarURLLength || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return Profile{}, ErrInvalidAvatarURL
+		}
+		profile.AvatarURL = v
+	}
+
+	return profile, nil
+}
+
+func trimmed(v *string) *string {
+	if v == nil {
+		return nil
+	}
+	s := strings.TrimSpace(*v)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
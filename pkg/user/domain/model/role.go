@@ -0,0 +1,51 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrInvalidRole       = errors.New("invalid role")
+	ErrInvalidPermission = errors.New("invalid permission")
+)
+
+// Role is a named set of permissions assigned to users
+type Role struct {
+	Name        string
+	Permissions []string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+var (
+	roleRegexp       = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+	permissionRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]{0,127}$`)
+)
+
+// NewRoleName validates role name, role names are case-insensitive and stored in lower case
+func NewRoleName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !roleRegexp.MatchString(name) {
+		return "", ErrInvalidRole
+	}
+	return name, nil
+}
+
+// NewPermission validates permission, permissions are case-insensitive and stored in lower case.
+// Permissions are matched exactly, so wildcards are not allowed
+func NewPermission(permission string) (string, error) {
+	permission = strings.ToLower(strings.TrimSpace(permission))
+	if !permissionRegexp.MatchString(permission) {
+		return "", ErrInvalidPermission
+	}
+	return permission, nil
+}
+
+type RoleRepository interface {
+	Store(role Role) error
+	Find(name string) (*Role, error)
+}
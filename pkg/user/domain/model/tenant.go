@@ -0,0 +1,22 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+)
+
+// DefaultTenantID is tenant of users created before tenants were introduced and of callers not passing tenant
+const DefaultTenantID = "default"
+
+var ErrInvalidTenant = errors.New("invalid tenant")
+
+// tenant is a word of routing keys and a part of lock names, so it is short and has neither dots nor underscores
+var tenantIDRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// NewTenantID validates identifier of tenant, logins, contacts and identities of users are unique within tenant
+func NewTenantID(tenantID string) (string, error) {
+	if !tenantIDRegexp.MatchString(tenantID) {
+		return "", ErrInvalidTenant
+	}
+	return tenantID, nil
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAPITokenNotFound          = errors.New("api token not found")
+	ErrAPITokenNameAlreadyUsed   = errors.New("api token name already used")
+	ErrInvalidAPITokenName       = errors.New("invalid api token name")
+	ErrInvalidAPITokenExpiration = errors.New("invalid api token expiration")
+	// ErrInvalidAPIToken is returned for unknown, expired and revoked tokens alike
+	ErrInvalidAPIToken = errors.New("invalid api token")
+)
+
+const maxAPITokenNameLength = 255
+
+// APIToken authenticates scripts acting on behalf of user, only hash of token is kept
+type APIToken struct {
+	TokenID uuid.UUID
+	// Name is unique among tokens of user
+	Name       string
+	Scopes     []string
+	TokenHash  string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+func (t APIToken) Expired(currentTime time.Time) bool {
+	return t.ExpiresAt != nil && !currentTime.Before(*t.ExpiresAt)
+}
+
+// NewAPITokenName trims token name, names are compared case-insensitively
+func NewAPITokenName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxAPITokenNameLength {
+		return "", ErrInvalidAPITokenName
+	}
+	return name, nil
+}
+
+// NewAPITokenScopes validates scopes, scopes have the same form as role permissions
+func NewAPITokenScopes(scopes []string) ([]string, error) {
+	result := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		scope, err := NewPermission(s)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, scope)
+	}
+	return result, nil
+}
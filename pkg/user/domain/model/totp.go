@@ -0,0 +1,37 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrTOTPAlreadyEnrolled = errors.New("totp already enrolled")
+	ErrTOTPNotEnrolled     = errors.New("totp not enrolled")
+	ErrInvalidTOTPCode     = errors.New("invalid totp code")
+	// ErrTOTPNotConfigured is returned when service has no key to encrypt totp secrets
+	ErrTOTPNotConfigured = errors.New("totp is not configured")
+)
+
+// TOTPFactor is time-based one-time password factor of user, it takes effect once confirmed with the first code
+type TOTPFactor struct {
+	// EncryptedSecret is secret encrypted with key of service, ciphertext is bound to user
+	EncryptedSecret []byte
+	ConfirmedAt     *time.Time
+	// LastUsedStep is time step of last accepted code, codes of this and earlier steps are rejected as replayed
+	LastUsedStep int64
+	// RecoveryCodeHashes are hashes of unused one-time recovery codes
+	RecoveryCodeHashes []string
+	// FailedAttempts counts failed verifications in a row, limits are shared with password lockout
+	FailedAttempts int
+	LockedUntil    *time.Time
+	CreatedAt      time.Time
+}
+
+func (f TOTPFactor) Confirmed() bool {
+	return f.ConfirmedAt != nil
+}
+
+func (f TOTPFactor) Locked(currentTime time.Time) bool {
+	return f.LockedUntil != nil && currentTime.Before(*f.LockedUntil)
+}
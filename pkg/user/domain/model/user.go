@@ -2,16 +2,23 @@ package model
 
 import (
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 var (
-	ErrUserNotFound            = errors.New("user not found")
-	ErrUserLoginAlreadyUsed    = errors.New("user login already used")
-	ErrUserEmailAlreadyUsed    = errors.New("user email already used")
-	ErrUserTelegramAlreadyUsed = errors.New("user telegram already used")
+	ErrUserNotFound                = errors.New("user not found")
+	ErrUserLoginAlreadyUsed        = errors.New("user login already used")
+	ErrUserNotDeleted              = errors.New("user not deleted")
+	ErrInvalidUserStatus           = errors.New("invalid user status")
+	ErrInvalidUserStatusTransition = errors.New("invalid user status transition")
+	ErrInvalidStatusExpiration     = errors.New("invalid status expiration")
+	ErrUserStatusOverridden        = errors.New("user status is set manually")
+	ErrMergeSameUser               = errors.New("user can not be merged into itself")
+	ErrMergeDeletedUser            = errors.New("deleted user can not be merged")
+	ErrUserMerged                  = errors.New("user is merged into another user")
 )
 
 type UserStatus int
@@ -20,24 +27,98 @@ const (
 	Blocked UserStatus = iota
 	Active
 	Deleted
+	// Pending is status of user without verified contacts
+	Pending
+	// Suspended is status of user whose access is temporarily restricted
+	Suspended
 )
 
+// statusTransitions lists statuses reachable by status update,
+// Deleted is entered and left only by deletion and restoration of user
+var statusTransitions = map[UserStatus][]UserStatus{
+	Pending:   {Active, Blocked, Suspended},
+	Active:    {Pending, Blocked, Suspended},
+	Blocked:   {Pending, Active},
+	Suspended: {Pending, Active, Blocked},
+}
+
+func NewUserStatus(status int) (UserStatus, error) {
+	switch UserStatus(status) {
+	case Blocked, Active, Deleted, Pending, Suspended:
+		return UserStatus(status), nil
+	default:
+		return 0, ErrInvalidUserStatus
+	}
+}
+
+// CanTransitTo reports whether status can be changed to next by status update
+func (s UserStatus) CanTransitTo(next UserStatus) bool {
+	return slices.Contains(statusTransitions[s], next)
+}
+
+// CanExpire reports whether status can be set temporarily
+func (s UserStatus) CanExpire() bool {
+	return s == Blocked || s == Suspended
+}
+
+// StatusSource tells who set user status, status set manually is not recomputed by the system
+type StatusSource int
+
+const (
+	StatusSourceSystem StatusSource = iota
+	StatusSourceManual
+)
+
+// StatusExpiration describes temporary status, RestoreStatus and RestoreStatusSource are set back when status expires
+type StatusExpiration struct {
+	ExpiresAt           time.Time
+	RestoreStatus       UserStatus
+	RestoreStatusSource StatusSource
+}
+
 type User struct {
-	UserID    uuid.UUID
-	Status    UserStatus
-	Login     string
-	Email     *string
-	Telegram  *string
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt *time.Time
+	UserID uuid.UUID
+	// TenantID is set on creation and never changes, users are visible only within their tenant
+	TenantID     string
+	Status       UserStatus
+	StatusSource StatusSource
+	// StatusExpiration is set when status is temporary
+	StatusExpiration *StatusExpiration
+	Login            string
+	Profile          Profile
+	Contacts         []Contact
+	Attributes       []Attribute
+	Labels           []string
+	// NotificationPreferences are sorted by channel and category, each pair occurs once
+	NotificationPreferences []NotificationPreference
+	// Roles are names of roles assigned to user
+	Roles []string
+	// ContactVerifications are pending verifications of user contacts
+	ContactVerifications []ContactVerification
+	// Credentials are set when user has password
+	Credentials *Credentials
+	APITokens   []APIToken
+	// TOTP is set when user has enrolled or is enrolling second factor
+	TOTP       *TOTPFactor
+	Identities []Identity
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  *time.Time
+	// MergedInto is set for deleted user whose contacts and attributes were moved to another user
+	MergedInto *uuid.UUID
+	// Version is incremented on every change of user, events about user carry version it got by the change
+	Version int64
 }
 
+// FindSpec looks user up by canonical forms of login and contact, by hash of api token or by linked identity.
+// TenantID restricts search to users of tenant, users of all tenants are searched when it is nil
 type FindSpec struct {
-	UserID   *uuid.UUID
-	Login    *string
-	Email    *string
-	Telegram *string
+	TenantID     *string
+	UserID       *uuid.UUID
+	Login        *string
+	Contact      *ContactSpec
+	APITokenHash *string
+	Identity     *IdentitySpec
 }
 
 type UserRepository interface {
@@ -45,4 +126,6 @@ type UserRepository interface {
 	Store(user User) error
 	Find(spec FindSpec) (*User, error)
 	HardDelete(userID uuid.UUID) error
+	// StoreAPITokenUsage records last usage of token apart from user, usage is not a change of user
+	StoreAPITokenUsage(tokenID uuid.UUID, usedAt time.Time) error
 }
@@ -0,0 +1,34 @@
+package model
+
+import "testing"
+
+func TestUserStatusCanTransitTo(t *testing.T) {
+	tests := []struct {
+		status UserStatus
+		next   UserStatus
+		want   bool
+	}{
+		{status: Pending, next: Active, want: true},
+		{status: Pending, next: Blocked, want: true},
+		{status: Pending, next: Suspended, want: true},
+		{status: Active, next: Pending, want: true},
+		{status: Active, next: Blocked, want: true},
+		{status: Active, next: Suspended, want: true},
+		{status: Blocked, next: Pending, want: true},
+		{status: Blocked, next: Active, want: true},
+		{status: Blocked, next: Suspended},
+		{status: Suspended, next: Pending, want: true},
+		{status: Suspended, next: Active, want: true},
+		{status: Suspended, next: Blocked, want: true},
+		{status: Active, next: Active},
+		{status: Active, next: Deleted},
+		{status: Blocked, next: Deleted},
+		{status: Deleted, next: Active},
+		{status: Deleted, next: Pending},
+	}
+	for _, tt := range tests {
+		if got := tt.status.CanTransitTo(tt.next); got != tt.want {
+			t.Errorf("%d.CanTransitTo(%d) = %v, want %v", tt.status, tt.next, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"sort"
+
+	"userservice/pkg/user/domain/model"
+)
+
+// diffAttributes returns attributes that were added or changed value and keys of removed attributes
+func diffAttributes(current, next []model.Attribute) (updated []model.Attribute, removed []string) {
+	currentByKey := make(map[string]model.Attribute, len(current))
+	for _, attribute := range current {
+		currentByKey[attribute.Key] = attribute
+	}
+	nextKeys := make(map[string]struct{}, len(next))
+	for _, attribute := range next {
+		nextKeys[attribute.Key] = struct{}{}
+		if a, ok := currentByKey[attribute.Key]; !ok || a != attribute {
+			updated = append(updated, attribute)
+		}
+	}
+	for _, attribute := range current {
+		if _, ok := nextKeys[attribute.Key]; !ok {
+			removed = append(removed, attribute.Key)
+		}
+	}
+	return updated, removed
+}
+
+// diffLabels returns added and removed labels
+func diffLabels(current, next []string) (added, removed []string) {
+	currentSet := make(map[string]struct{}, len(current))
+	for _, label := range current {
+		currentSet[label] = struct{}{}
+	}
+	nextSet := make(map[string]struct{}, len(next))
+	for _, label := range next {
+		nextSet[label] = struct{}{}
+		if _, ok := currentSet[label]; !ok {
+			added = append(added, label)
+		}
+	}
+	for _, label := range current {
+		if _, ok := nextSet[label]; !ok {
+			removed = append(removed, label)
+		}
+	}
+	return added, removed
+}
+
+func uniqueSorted(values []string) []string {
+	set := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if _, ok := set[v]; ok {
+			continue
+		}
+		set[v] = struct{}{}
+		result = append(result, v)
+	}
+	sort.Strings(result)
+	return result
+}
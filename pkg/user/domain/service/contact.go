@@ -0,0 +1,91 @@
+package service
+
+import "userservice/pkg/user/domain/model"
+
+// resolveContacts checks that contacts are unique and each type has exactly one primary contact,
+// first contact of type becomes primary when none marked
+func resolveContacts(contacts []model.Contact) ([]model.Contact, error) {
+	result := make([]model.Contact, 0, len(contacts))
+	seen := make(map[model.ContactSpec]struct{}, len(contacts))
+	primaries := make(map[model.ContactType]bool)
+	for _, contact := range contacts {
+		key := contactKey(contact)
+		if _, ok := seen[key]; ok {
+			return nil, model.ErrInvalidContact
+		}
+		seen[key] = struct{}{}
+
+		if contact.Primary {
+			if primaries[contact.Type] {
+				return nil, model.ErrInvalidContact
+			}
+			primaries[contact.Type] = true
+		}
+		result = append(result, contact)
+	}
+
+	for i := range result {
+		if !primaries[result[i].Type] {
+			result[i].Primary = true
+			primaries[result[i].Type] = true
+		}
+	}
+	return result, nil
+}
+
+// keepVerifiedAt copies verification time of already stored contacts, new contacts are unverified
+func keepVerifiedAt(current, next []model.Contact) []model.Contact {
+	for i := range next {
+		next[i].VerifiedAt = nil
+		if c := findContact(current, next[i].Type, next[i].Canonical); c != nil {
+			next[i].VerifiedAt = c.VerifiedAt
+		}
+	}
+	return next
+}
+
+// diffContacts returns contacts that were added or changed primary flag or display form and contacts that were removed
+func diffContacts(current, next []model.Contact) (updated, removed []model.Contact) {
+	currentByKey := make(map[model.ContactSpec]model.Contact, len(current))
+	for _, contact := range current {
+		currentByKey[contactKey(contact)] = contact
+	}
+	nextByKey := make(map[model.ContactSpec]struct{}, len(next))
+	for _, contact := range next {
+		nextByKey[contactKey(contact)] = struct{}{}
+		if c, ok := currentByKey[contactKey(contact)]; !ok || c.Primary != contact.Primary || c.Value != contact.Value {
+			updated = append(updated, contact)
+		}
+	}
+	for _, contact := range current {
+		if _, ok := nextByKey[contactKey(contact)]; !ok {
+			removed = append(removed, contact)
+		}
+	}
+	return updated, removed
+}
+
+func contactKey(contact model.Contact) model.ContactSpec {
+	return model.ContactSpec{
+		Type:  contact.Type,
+		Value: contact.Canonical,
+	}
+}
+
+func findContact(contacts []model.Contact, contactType model.ContactType, canonical string) *model.Contact {
+	for i := range contacts {
+		if contacts[i].Type == contactType && contacts[i].Canonical == canonical {
+			return &contacts[i]
+		}
+	}
+	return nil
+}
+
+func findContactVerification(verifications []model.ContactVerification, contactType model.ContactType, canonical string) int {
+	for i, verification := range verifications {
+		if verification.Type == contactType && verification.Value == canonical {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"userservice/pkg/user/domain/model"
+)
+
+func TestResolveContacts(t *testing.T) {
+	email := func(value string, primary bool) model.Contact {
+		return model.Contact{Type: model.ContactEmail, Value: value, Canonical: value, Primary: primary}
+	}
+	telegram := func(value string, primary bool) model.Contact {
+		return model.Contact{Type: model.ContactTelegram, Value: value, Canonical: value, Primary: primary}
+	}
+
+	tests := []struct {
+		name     string
+		contacts []model.Contact
+		want     []model.Contact
+		err      error
+	}{
+		{name: "no contacts", want: []model.Contact{}},
+		{
+			name:     "marked primary is kept",
+			contacts: []model.Contact{email("a@example.com", false), email("b@example.com", true)},
+			want:     []model.Contact{email("a@example.com", false), email("b@example.com", true)},
+		},
+		{
+			name:     "first contact of type becomes primary",
+			contacts: []model.Contact{email("a@example.com", false), telegram("user", false), email("b@example.com", false)},
+			want:     []model.Contact{email("a@example.com", true), telegram("user", true), email("b@example.com", false)},
+		},
+		{
+			name:     "primary is resolved per type",
+			contacts: []model.Contact{telegram("user", false), email("a@example.com", true), telegram("other", true)},
+			want:     []model.Contact{telegram("user", false), email("a@example.com", true), telegram("other", true)},
+		},
+		{
+			name:     "same canonical value of other type",
+			contacts: []model.Contact{email("user", false), telegram("user", false)},
+			want:     []model.Contact{email("user", true), telegram("user", true)},
+		},
+		{
+			name:     "duplicate contact",
+			contacts: []model.Contact{email("a@example.com", false), email("a@example.com", true)},
+			err:      model.ErrInvalidContact,
+		},
+		{
+			name:     "two primaries of type",
+			contacts: []model.Contact{email("a@example.com", true), email("b@example.com", true)},
+			err:      model.ErrInvalidContact,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveContacts(tt.contacts)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("resolveContacts() error = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveContacts() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"userservice/pkg/common/domain"
+	"userservice/pkg/user/domain/model"
+)
+
+type CredentialsService interface {
+	SetPassword(userID uuid.UUID, password string) error
+	// VerifyPassword returns false when password does not match,
+	// failed attempt is stored in this case so it must not be rolled back by caller
+	VerifyPassword(userID uuid.UUID, password string) (bool, error)
+}
+
+func NewCredentialsService(
+	userRepository model.UserRepository,
+	eventDispatcher domain.EventDispatcher,
+	lockout model.PasswordLockout,
+	tenantID *string,
+) CredentialsService {
+	return &credentialsService{
+		tenantID:        tenantID,
+		userRepository:  userRepository,
+		eventDispatcher: eventDispatcher,
+		lockout:         lockout,
+	}
+}
+
+type credentialsService struct {
+	// tenantID restricts users found by service, it is nil when service acts on users of all tenants
+	tenantID        *string
+	userRepository  model.UserRepository
+	eventDispatcher domain.EventDispatcher
+	lockout         model.PasswordLockout
+}
+
+func (c credentialsService) SetPassword(userID uuid.UUID, password string) error {
+	user, err := c.userRepository.Find(model.FindSpec{
+		TenantID: c.tenantID,
+		UserID:   &userID,
+	})
+	if err != nil {
+		return err
+	}
+	if user.Status == model.Deleted {
+		return model.ErrUserNotFound
+	}
+
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	currentTime := time.Now()
+	user.Credentials = &model.Credentials{
+		PasswordHash:      passwordHash,
+		PasswordChangedAt: currentTime,
+	}
+	user.UpdatedAt = currentTime
+	user.Version++
+	err = c.userRepository.Store(*user)
+	if err != nil {
+		return err
+	}
+
+	return c.eventDispatcher.Dispatch(&model.PasswordChanged{
+		UserID:    userID,
+		TenantID:  user.TenantID,
+		ChangedAt: currentTime,
+		Version:   user.Version,
+	})
+}
+
+func (c credentialsService) VerifyPassword(userID uuid.UUID, password string) (bool, error) {
+	user, err := c.userRepository.Find(model.FindSpec{
+		TenantID: c.tenantID,
+		UserID:   &userID,
+	})
+	if err != nil {
+		return false, err
+	}
+	if user.Status == model.Deleted || user.Credentials == nil {
+		CheckDummyPassword(password)
+		return false, nil
+	}
+
+	currentTime := time.Now()
+	if user.Credentials.Locked(currentTime) {
+		return false, model.ErrCredentialsLocked
+	}
+	ok, err := checkPassword(password, user.Credentials.PasswordHash)
+	if err != nil {
+		return false, err
+	}
+
+	// counters of attempts are not changes of user, so neither version nor update time is changed
+	credentials := user.Credentials
+	if ok {
+		if credentials.FailedAttempts == 0 && credentials.LockedUntil == nil {
+			return true, nil
+		}
+		credentials.FailedAttempts = 0
+		credentials.LockedUntil = nil
+		return true, c.userRepository.Store(*user)
+	}
+
+	credentials.FailedAttempts++
+	if credentials.FailedAttempts >= c.lockout.MaxFailedAttempts {
+		lockedUntil := currentTime.Add(c.lockout.Duration)
+		credentials.LockedUntil = &lockedUntil
+		credentials.FailedAttempts = 0
+	}
+	return false, c.userRepository.Store(*user)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"slices"
+
+	"userservice/pkg/user/domain/model"
+)
+
+// previousFields returns values user had in updated and removed fields, nil when none of fields had value.
+// Labels are not included as they have no value besides name
+func previousFields(user model.User, updated *model.UpdatedFields, removed *model.RemovedFields) *model.UpdatedFields {
+	if updated == nil {
+		updated = &model.UpdatedFields{}
+	}
+	if removed == nil {
+		removed = &model.RemovedFields{}
+	}
+
+	var (
+		previous    model.UpdatedFields
+		hasPrevious bool
+	)
+	if updated.Status != nil {
+		status := user.Status
+		previous.Status = &status
+		hasPrevious = true
+	}
+	if updated.StatusSource != nil {
+		source := user.StatusSource
+		previous.StatusSource = &source
+		hasPrevious = true
+	}
+	if (updated.StatusExpiresAt != nil || removed.StatusExpiresAt) && user.StatusExpiration != nil {
+		expiresAt := user.StatusExpiration.ExpiresAt
+		previous.StatusExpiresAt = &expiresAt
+		hasPrevious = true
+	}
+	previousField := func(current *string, updatedField *string, removedField bool, previousField **string) {
+		if (updatedField != nil || removedField) && current != nil {
+			v := *current
+			*previousField = &v
+			hasPrevious = true
+		}
+	}
+	previousField(user.Profile.DisplayName, updated.DisplayName, removed.DisplayName, &previous.DisplayName)
+	previousField(user.Profile.Locale, updated.Locale, removed.Locale, &previous.Locale)
+	previousField(user.Profile.Timezone, updated.Timezone, removed.Timezone, &previous.Timezone)
+	previousField(user.Profile.AvatarURL, updated.AvatarURL, removed.AvatarURL, &previous.AvatarURL)
+
+	for _, contact := range slices.Concat(updated.Contacts, removed.Contacts) {
+		if c := findContact(user.Contacts, contact.Type, contact.Canonical); c != nil {
+			previous.Contacts = append(previous.Contacts, *c)
+			hasPrevious = true
+		}
+	}
+
+	keys := slices.Clone(removed.Attributes)
+	for _, attribute := range updated.Attributes {
+		keys = append(keys, attribute.Key)
+	}
+	for _, attribute := range user.Attributes {
+		if slices.Contains(keys, attribute.Key) {
+			previous.Attributes = append(previous.Attributes, attribute)
+			hasPrevious = true
+		}
+	}
+
+	for _, preference := range slices.Concat(updated.NotificationPreferences, removed.NotificationPreferences) {
+		if p := findNotificationPreference(user.NotificationPreferences, preference); p != nil {
+			previous.NotificationPreferences = append(previous.NotificationPreferences, *p)
+			hasPrevious = true
+		}
+	}
+
+	if !hasPrevious {
+		return nil
+	}
+	return &previous
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"userservice/pkg/common/domain"
+	"userservice/pkg/user/domain/model"
+)
+
+type IdentityService interface {
+	// LinkIdentity links identity to user, linking identity already linked to the same user does nothing
+	LinkIdentity(userID uuid.UUID, identity model.IdentitySpec) error
+	UnlinkIdentity(userID uuid.UUID, identity model.IdentitySpec) error
+}
+
+func NewIdentityService(
+	userRepository model.UserRepository,
+	eventDispatcher domain.EventDispatcher,
+	tenantID *string,
+) IdentityService {
+	return &identityService{
+		tenantID:        tenantID,
+		userRepository:  userRepository,
+		eventDispatcher: eventDispatcher,
+	}
+}
+
+type identityService struct {
+	// tenantID restricts users found by service, it is nil when service acts on users of all tenants
+	tenantID        *string
+	userRepository  model.UserRepository
+	eventDispatcher domain.EventDispatcher
+}
+
+func (i identityService) LinkIdentity(userID uuid.UUID, identity model.IdentitySpec) error {
+	user, err := i.userRepository.Find(model.FindSpec{
+		TenantID: i.tenantID,
+		UserID:   &userID,
+	})
+	if err != nil {
+		return err
+	}
+	if user.Status == model.Deleted {
+		return model.ErrUserNotFound
+	}
+
+	userWithIdentity, err := i.userRepository.Find(model.FindSpec{
+		TenantID: &user.TenantID,
+		Identity: &identity,
+	})
+	if err != nil && !errors.Is(err, model.ErrUserNotFound) {
+		return err
+	}
+	if userWithIdentity != nil {
+		if userWithIdentity.UserID == userID {
+			return nil
+		}
+		return model.ErrIdentityAlreadyLinked
+	}
+
+	currentTime := time.Now()
+	user.Identities = append(user.Identities, model.Identity{
+		Issuer:   identity.Issuer,
+		Subject:  identity.Subject,
+		LinkedAt: currentTime,
+	})
+	user.UpdatedAt = currentTime
+	user.Version++
+	err = i.userRepository.Store(*user)
+	if err != nil {
+		return err
+	}
+
+	return i.eventDispatcher.Dispatch(&model.IdentityLinked{
+		UserID:   userID,
+		TenantID: user.TenantID,
+		Issuer:   identity.Issuer,
+		Subject:  identity.Subject,
+		LinkedAt: currentTime,
+		Version:  user.Version,
+	})
+}
+
+func (i identityService) UnlinkIdentity(userID uuid.UUID, identity model.IdentitySpec) error {
+	user, err := i.userRepository.Find(model.FindSpec{
+		TenantID: i.tenantID,
+		UserID:   &userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	index := slices.IndexFunc(user.Identities, func(linked model.Identity) bool {
+		return linked.Issuer == identity.Issuer && linked.Subject == identity.Subject
+	})
+	if index == -1 {
+		return model.ErrIdentityNotFound
+	}
+
+	currentTime := time.Now()
+	user.Identities = slices.Delete(user.Identities, index, index+1)
+	user.UpdatedAt = currentTime
+	user.Version++
+	err = i.userRepository.Store(*user)
+	if err != nil {
+		return err
+	}
+
+	return i.eventDispatcher.Dispatch(&model.IdentityUnlinked{
+		UserID:     userID,
+		TenantID:   user.TenantID,
+		Issuer:     identity.Issuer,
+		Subject:    identity.Subject,
+		UnlinkedAt: currentTime,
+		Version:    user.Version,
+	})
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"cmp"
+	"slices"
+
+	"userservice/pkg/user/domain/model"
+)
+
+// sortNotificationPreferences sorts preferences by channel and category, it fails when the pair occurs twice
+func sortNotificationPreferences(preferences []model.NotificationPreference) ([]model.NotificationPreference, error) {
+	result := slices.Clone(preferences)
+	slices.SortFunc(result, compareNotificationPreferences)
+	for i := 1; i < len(result); i++ {
+		if compareNotificationPreferences(result[i-1], result[i]) == 0 {
+			return nil, model.ErrInvalidNotificationPreference
+		}
+	}
+	return result, nil
+}
+
+// diffNotificationPreferences returns preferences that were added or changed enabled flag and removed preferences
+func diffNotificationPreferences(current, next []model.NotificationPreference) (updated, removed []model.NotificationPreference) {
+	for _, preference := range next {
+		p := findNotificationPreference(current, preference)
+		if p == nil || p.Enabled != preference.Enabled {
+			updated = append(updated, preference)
+		}
+	}
+	for _, preference := range current {
+		if findNotificationPreference(next, preference) == nil {
+			removed = append(removed, preference)
+		}
+	}
+	return updated, removed
+}
+
+// findNotificationPreference finds preference for the same channel and category as preference
+func findNotificationPreference(preferences []model.NotificationPreference, preference model.NotificationPreference) *model.NotificationPreference {
+	i := slices.IndexFunc(preferences, func(p model.NotificationPreference) bool {
+		return compareNotificationPreferences(p, preference) == 0
+	})
+	if i == -1 {
+		return nil
+	}
+	return &preferences[i]
+}
+
+func compareNotificationPreferences(a, b model.NotificationPreference) int {
+	return cmp.Or(cmp.Compare(a.Channel, b.Channel), cmp.Compare(a.Category, b.Category))
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"userservice/pkg/common/domain"
+	"userservice/pkg/user/domain/model"
+)
+
+type OrganizationService interface {
+	CreateOrganization(name string, ownerID uuid.UUID) (uuid.UUID, error)
+	StoreMember(organizationID, userID uuid.UUID, role model.MembershipRole, displayName *string) error
+	RemoveMember(organizationID, userID uuid.UUID) error
+	// RemoveUserMemberships removes user from all organizations before user is hard deleted,
+	// it fails when user is the only owner of organization as ownership has to be passed first
+	RemoveUserMemberships(userID uuid.UUID) error
+	// TransferUserMemberships moves memberships of source user to target user when users are merged and returns
+	// organizations they were moved in. Target which is already member keeps its display name and the higher role
+	TransferUserMemberships(sourceID, targetID uuid.UUID) ([]uuid.UUID, error)
+}
+
+func NewOrganizationService(
+	organizationRepository model.OrganizationRepository,
+	eventDispatcher domain.EventDispatcher,
+	tenantID *string,
+) OrganizationService {
+	return &organizationService{
+		tenantID:               tenantID,
+		organizationRepository: organizationRepository,
+		eventDispatcher:        eventDispatcher,
+	}
+}
+
+type organizationService struct {
+	// tenantID restricts organizations found by service, it is nil when service acts on organizations of all tenants
+	tenantID               *string
+	organizationRepository model.OrganizationRepository
+	eventDispatcher        domain.EventDispatcher
+}
+
+// CreateOrganization creates organization within tenant of service, names are unique within tenant
+func (o organizationService) CreateOrganization(name string, ownerID uuid.UUID) (uuid.UUID, error) {
+	if o.tenantID == nil {
+		return uuid.Nil, model.ErrInvalidTenant
+	}
+	_, err := o.organizationRepository.Find(model.OrganizationFindSpec{
+		TenantID: o.tenantID,
+		Name:     &name,
+	})
+	if err != nil && !errors.Is(err, model.ErrOrganizationNotFound) {
+		return uuid.Nil, err
+	}
+	if err == nil {
+		return uuid.Nil, model.ErrOrganizationNameAlreadyUsed
+	}
+
+	organizationID, err := o.organizationRepository.NextID()
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	currentTime := time.Now()
+	owner := model.Member{
+		UserID:   ownerID,
+		Role:     model.MembershipOwner,
+		JoinedAt: currentTime,
+	}
+	err = o.organizationRepository.Store(model.Organization{
+		OrganizationID: organizationID,
+		TenantID:       *o.tenantID,
+		Name:           name,
+		Members:        []model.Member{owner},
+		CreatedAt:      currentTime,
+		UpdatedAt:      currentTime,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = o.eventDispatcher.Dispatch(&model.OrganizationCreated{
+		OrganizationID: organizationID,
+		TenantID:       *o.tenantID,
+		Name:           name,
+		OwnerID:        ownerID,
+		CreatedAt:      currentTime,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return organizationID, o.eventDispatcher.Dispatch(&model.OrganizationMemberStored{
+		OrganizationID: organizationID,
+		TenantID:       *o.tenantID,
+		Member:         owner,
+		UpdatedAt:      currentTime,
+	})
+}
+
+// StoreMember adds user to organization or changes role and display name of existing member
+func (o organizationService) StoreMember(organizationID, userID uuid.UUID, role model.MembershipRole, displayName *string) error {
+	organization, err := o.organizationRepository.Find(model.OrganizationFindSpec{
+		TenantID:       o.tenantID,
+		OrganizationID: &organizationID,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, m := range organization.Members {
+		if m.UserID != userID && displayName != nil && m.DisplayName != nil && strings.EqualFold(*m.DisplayName, *displayName) {
+			return model.ErrMemberDisplayNameAlreadyUsed
+		}
+	}
+
+	currentTime := time.Now()
+	i := findMember(organization.Members, userID)
+	if i == -1 {
+		organization.Members = append(organization.Members, model.Member{
+			UserID:   userID,
+			JoinedAt: currentTime,
+		})
+		i = len(organization.Members) - 1
+	} else if organization.Members[i].Role == role && equalPtr(organization.Members[i].DisplayName, displayName) {
+		return nil
+	}
+	organization.Members[i].Role = role
+	organization.Members[i].DisplayName = displayName
+	if !hasOwner(organization.Members) {
+		return model.ErrOrganizationWithoutOwner
+	}
+
+	organization.UpdatedAt = currentTime
+	err = o.organizationRepository.Store(*organization)
+	if err != nil {
+		return err
+	}
+
+	return o.eventDispatcher.Dispatch(&model.OrganizationMemberStored{
+		OrganizationID: organizationID,
+		TenantID:       organization.TenantID,
+		Member:         organization.Members[i],
+		UpdatedAt:      currentTime,
+	})
+}
+
+func (o organizationService) RemoveMember(organizationID, userID uuid.UUID) error {
+	organization, err := o.organizationRepository.Find(model.OrganizationFindSpec{
+		TenantID:       o.tenantID,
+		OrganizationID: &organizationID,
+	})
+	if err != nil {
+		return err
+	}
+
+	i := findMember(organization.Members, userID)
+	if i == -1 {
+		return model.ErrOrganizationMemberNotFound
+	}
+	organization.Members = slices.Delete(organization.Members, i, i+1)
+	if !hasOwner(organization.Members) {
+		return model.ErrOrganizationWithoutOwner
+	}
+
+	currentTime := time.Now()
+	organization.UpdatedAt = currentTime
+	err = o.organizationRepository.Store(*organization)
+	if err != nil {
+		return err
+	}
+
+	return o.eventDispatcher.Dispatch(&model.OrganizationMemberRemoved{
+		OrganizationID: organizationID,
+		TenantID:       organization.TenantID,
+		UserID:         userID,
+		RemovedAt:      currentTime,
+	})
+}
+
+func (o organizationService) RemoveUserMemberships(userID uuid.UUID) error {
+	organizations, err := o.organizationRepository.FindByMember(userID)
+	if err != nil {
+		return err
+	}
+
+	currentTime := time.Now()
+	for _, organization := range organizations {
+		organization.Members = slices.DeleteFunc(organization.Members, func(m model.Member) bool {
+			return m.UserID == userID
+		})
+		if !hasOwner(organization.Members) {
+			return model.ErrOrganizationWithoutOwner
+		}
+
+		organization.UpdatedAt = currentTime
+		err = o.organizationRepository.Store(organization)
+		if err != nil {
+			return err
+		}
+
+		err = o.eventDispatcher.Dispatch(&model.OrganizationMemberRemoved{
+			OrganizationID: organization.OrganizationID,
+			TenantID:       organization.TenantID,
+			UserID:         userID,
+			RemovedAt:      currentTime,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o organizationService) TransferUserMemberships(sourceID, targetID uuid.UUID) ([]uuid.UUID, error) {
+	if sourceID == targetID {
+		return nil, model.ErrMergeSameUser
+	}
+	organizations, err := o.organizationRepository.FindByMember(sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTime := time.Now()
+	organizationIDs := make([]uuid.UUID, 0, len(organizations))
+	for _, organization := range organizations {
+		i := findMember(organization.Members, sourceID)
+		source := organization.Members[i]
+		organization.Members = slices.Delete(organization.Members, i, i+1)
+		j := findMember(organization.Members, targetID)
+		if j == -1 {
+			source.UserID = targetID
+			organization.Members = append(organization.Members, source)
+			j = len(organization.Members) - 1
+		} else if source.Role == model.MembershipOwner {
+			organization.Members[j].Role = model.MembershipOwner
+		}
+
+		organization.UpdatedAt = currentTime
+		err = o.organizationRepository.Store(organization)
+		if err != nil {
+			return nil, err
+		}
+
+		err = o.eventDispatcher.Dispatch(&model.OrganizationMemberRemoved{
+			OrganizationID: organization.OrganizationID,
+			TenantID:       organization.TenantID,
+			UserID:         sourceID,
+			RemovedAt:      currentTime,
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = o.eventDispatcher.Dispatch(&model.OrganizationMemberStored{
+			OrganizationID: organization.OrganizationID,
+			TenantID:       organization.TenantID,
+			Member:         organization.Members[j],
+			UpdatedAt:      currentTime,
+		})
+		if err != nil {
+			return nil, err
+		}
+		organizationIDs = append(organizationIDs, organization.OrganizationID)
+	}
+	return organizationIDs, nil
+}
+
+func findMember(members []model.Member, userID uuid.UUID) int {
+	return slices.IndexFunc(members, func(m model.Member) bool {
+		return m.UserID == userID
+	})
+}
+
+func hasOwner(members []model.Member) bool {
+	return slices.ContainsFunc(members, func(m model.Member) bool {
+		return m.Role == model.MembershipOwner
+	})
+}
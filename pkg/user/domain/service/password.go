@@ -0,0 +1,92 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024
+	argon2Threads = 2
+	argon2KeyLen  = 32
+	argon2SaltLen = 16
+)
+
+var errInvalidPasswordHash = errors.New("invalid password hash")
+
+// dummyPasswordHash is hash with current parameters checked when user has no password,
+// so failure for unknown user costs the same as failure for wrong password
+var dummyPasswordHash = sync.OnceValues(func() (string, error) {
+	return hashPassword("")
+})
+
+// CheckDummyPassword takes as long as check of wrong password, it is called when there is no hash to check password against
+// so response time does not tell whether login exists
+func CheckDummyPassword(password string) {
+	hash, err := dummyPasswordHash()
+	if err != nil {
+		return
+	}
+	_, _ = checkPassword(password, hash)
+}
+
+// hashPassword hashes password with argon2id, hash is encoded in PHC format with its parameters
+// so parameters can be changed without breaking verification of already stored hashes
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		argon2Memory,
+		argon2Time,
+		argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// checkPassword compares password with encoded hash in constant time
+func checkPassword(password, encodedHash string) (bool, error) {
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, errors.WithStack(errInvalidPasswordHash)
+	}
+	var version int
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return false, errors.WithStack(errInvalidPasswordHash)
+	}
+	var (
+		memory     uint32
+		iterations uint32
+		threads    uint8
+	)
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads)
+	if err != nil {
+		return false, errors.WithStack(errInvalidPasswordHash)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, errors.WithStack(errInvalidPasswordHash)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, errors.WithStack(errInvalidPasswordHash)
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, iterations, memory, threads, uint32(len(key))) // nolint:gosec
+	return subtle.ConstantTimeCompare(key, otherKey) == 1, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := hashPassword("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(hash, "$")
+
+	tests := []struct {
+		name     string
+		password string
+		hash     string
+		match    bool
+		err      error
+	}{
+		{name: "same password", password: "correct horse battery staple", hash: hash, match: true},
+		{name: "other password", password: "correct horse battery stapler", hash: hash},
+		{name: "empty password", password: "", hash: hash},
+		{name: "not phc format", password: "correct horse battery staple", hash: "plain", err: errInvalidPasswordHash},
+		{name: "other algorithm", password: "correct horse battery staple", hash: strings.Replace(hash, "argon2id", "argon2i", 1), err: errInvalidPasswordHash},
+		{name: "other version", password: "correct horse battery staple", hash: strings.Replace(hash, "v=19", "v=16", 1), err: errInvalidPasswordHash},
+		{name: "corrupted parameters", password: "correct horse battery staple", hash: strings.Replace(hash, parts[3], "m=x", 1), err: errInvalidPasswordHash},
+		{name: "corrupted salt", password: "correct horse battery staple", hash: strings.Replace(hash, parts[4], "!"+parts[4], 1), err: errInvalidPasswordHash},
+		{name: "corrupted key", password: "correct horse battery staple", hash: strings.Replace(hash, parts[5], "!"+parts[5], 1), err: errInvalidPasswordHash},
+		{name: "missing part", password: "correct horse battery staple", hash: strings.Join(parts[:5], "$"), err: errInvalidPasswordHash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := checkPassword(tt.password, tt.hash)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("checkPassword() error = %v, want %v", err, tt.err)
+			}
+			if match != tt.match {
+				t.Errorf("checkPassword() = %v, want %v", match, tt.match)
+			}
+		})
+	}
+}
+
+func TestHashPasswordUsesSalt(t *testing.T) {
+	hash, err := hashPassword("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherHash, err := hashPassword("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash == otherHash {
+		t.Errorf("hashPassword() returned equal hashes %q for the same password", hash)
+	}
+}
+
+func TestDummyPasswordHash(t *testing.T) {
+	hash, err := dummyPasswordHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// dummy hash has to be valid, otherwise check fails at once without running argon2id
+	match, err := checkPassword("password", hash)
+	if err != nil {
+		t.Fatalf("checkPassword() error = %v", err)
+	}
+	if match {
+		t.Error("checkPassword() matched dummy hash")
+	}
+}
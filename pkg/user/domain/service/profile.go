@@ -0,0 +1,39 @@
+package service
+
+import "userservice/pkg/user/domain/model"
+
+// diffProfile returns changed and removed profile fields, nil when there are no changes of kind
+func diffProfile(current, next model.Profile) (*model.UpdatedFields, *model.RemovedFields) {
+	var (
+		updated    model.UpdatedFields
+		removed    model.RemovedFields
+		hasUpdated bool
+		hasRemoved bool
+	)
+	diffField := func(current, next *string, updatedField **string, removedField *bool) {
+		switch {
+		case next == nil && current != nil:
+			*removedField = true
+			hasRemoved = true
+		case next != nil && (current == nil || *current != *next):
+			*updatedField = next
+			hasUpdated = true
+		}
+	}
+	diffField(current.DisplayName, next.DisplayName, &updated.DisplayName, &removed.DisplayName)
+	diffField(current.Locale, next.Locale, &updated.Locale, &removed.Locale)
+	diffField(current.Timezone, next.Timezone, &updated.Timezone, &removed.Timezone)
+	diffField(current.AvatarURL, next.AvatarURL, &updated.AvatarURL, &removed.AvatarURL)
+
+	var (
+		updatedFields *model.UpdatedFields
+		removedFields *model.RemovedFields
+	)
+	if hasUpdated {
+		updatedFields = &updated
+	}
+	if hasRemoved {
+		removedFields = &removed
+	}
+	return updatedFields, removedFields
+}
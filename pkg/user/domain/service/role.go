@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"userservice/pkg/common/domain"
+	"userservice/pkg/user/domain/model"
+)
+
+type RoleService interface {
+	StoreRole(name string, permissions []string) error
+}
+
+func NewRoleService(
+	roleRepository model.RoleRepository,
+	eventDispatcher domain.EventDispatcher,
+) RoleService {
+	return &roleService{
+		roleRepository:  roleRepository,
+		eventDispatcher: eventDispatcher,
+	}
+}
+
+type roleService struct {
+	roleRepository  model.RoleRepository
+	eventDispatcher domain.EventDispatcher
+}
+
+// StoreRole creates role or replaces its permissions
+func (r roleService) StoreRole(name string, permissions []string) error {
+	permissions = uniqueSorted(permissions)
+
+	currentTime := time.Now()
+	role, err := r.roleRepository.Find(name)
+	if err != nil && !errors.Is(err, model.ErrRoleNotFound) {
+		return err
+	}
+	if err != nil {
+		role = &model.Role{
+			Name:      name,
+			CreatedAt: currentTime,
+		}
+	} else if slices.Equal(role.Permissions, permissions) {
+		return nil
+	}
+
+	role.Permissions = permissions
+	role.UpdatedAt = currentTime
+	err = r.roleRepository.Store(*role)
+	if err != nil {
+		return err
+	}
+
+	return r.eventDispatcher.Dispatch(&model.RoleUpdated{
+		Role:        name,
+		Permissions: permissions,
+		UpdatedAt:   currentTime,
+	})
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+
+	"github.com/pkg/errors"
+)
+
+var errInvalidCiphertext = errors.New("invalid ciphertext")
+
+// SecretCipher encrypts secrets of users kept by service, associated data binds ciphertext to its owner
+// so ciphertext copied to another user fails to decrypt
+type SecretCipher interface {
+	Encrypt(plaintext, associatedData []byte) ([]byte, error)
+	Decrypt(ciphertext, associatedData []byte) ([]byte, error)
+}
+
+// NewAESSecretCipher returns AES-GCM cipher, key must be 16, 24 or 32 bytes long
+func NewAESSecretCipher(key []byte) (SecretCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &aesSecretCipher{aead: aead}, nil
+}
+
+type aesSecretCipher struct {
+	aead cipher.AEAD
+}
+
+// Encrypt returns random nonce followed by sealed plaintext
+func (c aesSecretCipher) Encrypt(plaintext, associatedData []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func (c aesSecretCipher) Decrypt(ciphertext, associatedData []byte) ([]byte, error) {
+	if len(ciphertext) < c.aead.NonceSize() {
+		return nil, errors.WithStack(errInvalidCiphertext)
+	}
+	nonce, sealed := ciphertext[:c.aead.NonceSize()], ciphertext[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, sealed, associatedData)
+	return plaintext, errors.WithStack(err)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"userservice/pkg/common/domain"
+	"userservice/pkg/user/domain/model"
+)
+
+const (
+	apiTokenPrefix       = "usr_"
+	apiTokenSecretLength = 32
+	// apiTokenLastUsedPrecision limits writes of last usage time of frequently used tokens
+	apiTokenLastUsedPrecision = time.Minute
+)
+
+type APITokenService interface {
+	// CreateAPIToken returns identifier and token which is shown only once, only hash of token is stored
+	CreateAPIToken(userID uuid.UUID, name string, scopes []string, expiresAt *time.Time) (uuid.UUID, string, error)
+	RevokeAPIToken(userID, tokenID uuid.UUID) error
+	// ValidateAPIToken returns owner and scopes of token of active user and records token usage,
+	// it does not store user, so it needs no lock of user
+	ValidateAPIToken(token string) (uuid.UUID, []string, error)
+}
+
+func NewAPITokenService(
+	userRepository model.UserRepository,
+	eventDispatcher domain.EventDispatcher,
+	tenantID *string,
+) APITokenService {
+	return &apiTokenService{
+		tenantID:        tenantID,
+		userRepository:  userRepository,
+		eventDispatcher: eventDispatcher,
+	}
+}
+
+type apiTokenService struct {
+	// tenantID restricts users found by service, it is nil when service acts on users of all tenants
+	tenantID        *string
+	userRepository  model.UserRepository
+	eventDispatcher domain.EventDispatcher
+}
+
+func (a apiTokenService) CreateAPIToken(userID uuid.UUID, name string, scopes []string, expiresAt *time.Time) (uuid.UUID, string, error) {
+	currentTime := time.Now()
+	if expiresAt != nil && !expiresAt.After(currentTime) {
+		return uuid.Nil, "", model.ErrInvalidAPITokenExpiration
+	}
+
+	user, err := a.userRepository.Find(model.FindSpec{
+		TenantID: a.tenantID,
+		UserID:   &userID,
+	})
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	if user.Status == model.Deleted {
+		return uuid.Nil, "", model.ErrUserNotFound
+	}
+	if slices.ContainsFunc(user.APITokens, func(t model.APIToken) bool { return strings.EqualFold(t.Name, name) }) {
+		return uuid.Nil, "", model.ErrAPITokenNameAlreadyUsed
+	}
+
+	tokenID, err := uuid.NewV7()
+	if err != nil {
+		return uuid.Nil, "", errors.WithStack(err)
+	}
+	token, err := newAPIToken()
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	user.APITokens = append(user.APITokens, model.APIToken{
+		TokenID:   tokenID,
+		Name:      name,
+		Scopes:    scopes,
+		TokenHash: HashAPIToken(token),
+		ExpiresAt: expiresAt,
+		CreatedAt: currentTime,
+	})
+	user.UpdatedAt = currentTime
+	user.Version++
+	err = a.userRepository.Store(*user)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+
+	return tokenID, token, a.eventDispatcher.Dispatch(&model.APITokenCreated{
+		UserID:    userID,
+		TenantID:  user.TenantID,
+		TokenID:   tokenID,
+		Name:      name,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: currentTime,
+		Version:   user.Version,
+	})
+}
+
+func (a apiTokenService) RevokeAPIToken(userID, tokenID uuid.UUID) error {
+	user, err := a.userRepository.Find(model.FindSpec{
+		TenantID: a.tenantID,
+		UserID:   &userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(user.APITokens, func(t model.APIToken) bool { return t.TokenID == tokenID })
+	if i == -1 {
+		return model.ErrAPITokenNotFound
+	}
+
+	currentTime := time.Now()
+	user.APITokens = slices.Delete(user.APITokens, i, i+1)
+	user.UpdatedAt = currentTime
+	user.Version++
+	err = a.userRepository.Store(*user)
+	if err != nil {
+		return err
+	}
+
+	return a.eventDispatcher.Dispatch(&model.APITokenRevoked{
+		UserID:    userID,
+		TenantID:  user.TenantID,
+		TokenID:   tokenID,
+		RevokedAt: currentTime,
+		Version:   user.Version,
+	})
+}
+
+func (a apiTokenService) ValidateAPIToken(token string) (uuid.UUID, []string, error) {
+	tokenHash := HashAPIToken(token)
+	user, err := a.userRepository.Find(model.FindSpec{
+		TenantID:     a.tenantID,
+		APITokenHash: &tokenHash,
+	})
+	if errors.Is(err, model.ErrUserNotFound) {
+		return uuid.Nil, nil, model.ErrInvalidAPIToken
+	}
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	if user.Status != model.Active {
+		return uuid.Nil, nil, model.ErrInvalidAPIToken
+	}
+
+	i := slices.IndexFunc(user.APITokens, func(t model.APIToken) bool { return t.TokenHash == tokenHash })
+	currentTime := time.Now()
+	if i == -1 || user.APITokens[i].Expired(currentTime) {
+		return uuid.Nil, nil, model.ErrInvalidAPIToken
+	}
+
+	// usage of token is not a change of user, so neither version nor update time is changed
+	apiToken := user.APITokens[i]
+	if apiToken.LastUsedAt == nil || currentTime.Sub(*apiToken.LastUsedAt) >= apiTokenLastUsedPrecision {
+		err = a.userRepository.StoreAPITokenUsage(apiToken.TokenID, currentTime)
+		if err != nil {
+			return uuid.Nil, nil, err
+		}
+	}
+	return user.UserID, apiToken.Scopes, nil
+}
+
+// HashAPIToken returns hash api tokens are stored and looked up by, tokens are random so hash is not salted
+func HashAPIToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func newAPIToken() (string, error) {
+	secret := make([]byte, apiTokenSecretLength)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return apiTokenPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
@@ -0,0 +1,344 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" // nolint:gosec // totp of RFC 6238 supported by authenticator apps uses HMAC-SHA1
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"userservice/pkg/common/domain"
+	"userservice/pkg/user/domain/model"
+)
+
+const (
+	totpSecretLength = 20
+	totpPeriod       = 30
+	totpDigits       = 6
+	totpModulo       = 1_000_000
+	// totpSkew is number of steps around current one codes of which are accepted to tolerate clock drift
+	totpSkew = 1
+
+	recoveryCodeCount = 10
+	// recoveryCodeLength is number of base32 characters in recovery code, code is shown split in two halves
+	recoveryCodeLength = 10
+)
+
+type TOTPService interface {
+	// EnrollTOTP starts enrollment replacing unconfirmed one, it returns secret in base32 and otpauth URI for authenticator apps
+	EnrollTOTP(userID uuid.UUID) (secret, uri string, err error)
+	// ConfirmTOTP completes enrollment with the first code and returns recovery codes, they are shown only once
+	ConfirmTOTP(userID uuid.UUID, code string) ([]string, error)
+	// VerifyTOTP accepts code of authenticator app or unused recovery code and returns false on mismatch,
+	// failed attempt is stored in this case so it must not be rolled back by caller
+	VerifyTOTP(userID uuid.UUID, code string) (bool, error)
+	// RemoveTOTP removes confirmed factor only with its current code or unused recovery code and returns false on mismatch,
+	// failed attempt is stored as in VerifyTOTP, unconfirmed enrollment is removed without code
+	RemoveTOTP(userID uuid.UUID, code string) (bool, error)
+}
+
+// NewTOTPService returns service which rejects enrollment and verification when cipher is nil
+func NewTOTPService(
+	userRepository model.UserRepository,
+	eventDispatcher domain.EventDispatcher,
+	cipher SecretCipher,
+	issuer string,
+	lockout model.PasswordLockout,
+	tenantID *string,
+) TOTPService {
+	return &totpService{
+		tenantID:        tenantID,
+		userRepository:  userRepository,
+		eventDispatcher: eventDispatcher,
+		cipher:          cipher,
+		issuer:          issuer,
+		lockout:         lockout,
+	}
+}
+
+type totpService struct {
+	// tenantID restricts users found by service, it is nil when service acts on users of all tenants
+	tenantID        *string
+	userRepository  model.UserRepository
+	eventDispatcher domain.EventDispatcher
+	cipher          SecretCipher
+	issuer          string
+	lockout         model.PasswordLockout
+}
+
+func (t totpService) EnrollTOTP(userID uuid.UUID) (string, string, error) {
+	if t.cipher == nil {
+		return "", "", model.ErrTOTPNotConfigured
+	}
+	user, err := t.findUser(userID)
+	if err != nil {
+		return "", "", err
+	}
+	if user.TOTP != nil && user.TOTP.Confirmed() {
+		return "", "", model.ErrTOTPAlreadyEnrolled
+	}
+
+	secret := make([]byte, totpSecretLength)
+	_, err = rand.Read(secret)
+	if err != nil {
+		return "", "", errors.WithStack(err)
+	}
+	encryptedSecret, err := t.cipher.Encrypt(secret, userID[:])
+	if err != nil {
+		return "", "", err
+	}
+
+	// unconfirmed factor has no effect, so neither version nor update time is changed
+	user.TOTP = &model.TOTPFactor{
+		EncryptedSecret: encryptedSecret,
+		CreatedAt:       time.Now(),
+	}
+	err = t.userRepository.Store(*user)
+	if err != nil {
+		return "", "", err
+	}
+
+	encodedSecret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+	return encodedSecret, t.uri(user.Login, encodedSecret), nil
+}
+
+func (t totpService) ConfirmTOTP(userID uuid.UUID, code string) ([]string, error) {
+	if t.cipher == nil {
+		return nil, model.ErrTOTPNotConfigured
+	}
+	user, err := t.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTP == nil {
+		return nil, model.ErrTOTPNotEnrolled
+	}
+	if user.TOTP.Confirmed() {
+		return nil, model.ErrTOTPAlreadyEnrolled
+	}
+
+	currentTime := time.Now()
+	step, ok, err := t.matchCode(userID, *user.TOTP, code, currentTime)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, model.ErrInvalidTOTPCode
+	}
+
+	recoveryCodes := make([]string, 0, recoveryCodeCount)
+	recoveryCodeHashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		recoveryCode, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		recoveryCodes = append(recoveryCodes, recoveryCode)
+		recoveryCodeHashes = append(recoveryCodeHashes, hashRecoveryCode(recoveryCode))
+	}
+
+	user.TOTP.ConfirmedAt = &currentTime
+	user.TOTP.LastUsedStep = step
+	user.TOTP.RecoveryCodeHashes = recoveryCodeHashes
+	user.UpdatedAt = currentTime
+	user.Version++
+	err = t.userRepository.Store(*user)
+	if err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, t.eventDispatcher.Dispatch(&model.TOTPEnrolled{
+		UserID:     userID,
+		TenantID:   user.TenantID,
+		EnrolledAt: currentTime,
+		Version:    user.Version,
+	})
+}
+
+func (t totpService) VerifyTOTP(userID uuid.UUID, code string) (bool, error) {
+	if t.cipher == nil {
+		return false, model.ErrTOTPNotConfigured
+	}
+	user, err := t.findUser(userID)
+	if err != nil {
+		return false, err
+	}
+	if user.TOTP == nil || !user.TOTP.Confirmed() {
+		return false, model.ErrTOTPNotEnrolled
+	}
+
+	ok, err := t.checkCode(user, code, time.Now())
+	if err != nil {
+		return false, err
+	}
+	// usage of factor is not a change of user, so neither version nor update time is changed
+	return ok, t.userRepository.Store(*user)
+}
+
+func (t totpService) RemoveTOTP(userID uuid.UUID, code string) (bool, error) {
+	user, err := t.findUser(userID)
+	if err != nil {
+		return false, err
+	}
+	if user.TOTP == nil {
+		return false, model.ErrTOTPNotEnrolled
+	}
+
+	if !user.TOTP.Confirmed() {
+		user.TOTP = nil
+		return true, t.userRepository.Store(*user)
+	}
+
+	if t.cipher == nil {
+		return false, model.ErrTOTPNotConfigured
+	}
+	currentTime := time.Now()
+	ok, err := t.checkCode(user, code, currentTime)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		// counters of attempts are not changes of user, so neither version nor update time is changed
+		return false, t.userRepository.Store(*user)
+	}
+
+	user.TOTP = nil
+	user.UpdatedAt = currentTime
+	user.Version++
+	err = t.userRepository.Store(*user)
+	if err != nil {
+		return false, err
+	}
+
+	return true, t.eventDispatcher.Dispatch(&model.TOTPRemoved{
+		UserID:    userID,
+		TenantID:  user.TenantID,
+		RemovedAt: currentTime,
+		Version:   user.Version,
+	})
+}
+
+// checkCode matches code of authenticator app or unused recovery code against confirmed factor of user,
+// it updates used step, recovery codes and counters of failed attempts, user is stored by caller
+func (t totpService) checkCode(user *model.User, code string, currentTime time.Time) (bool, error) {
+	factor := user.TOTP
+	if factor.Locked(currentTime) {
+		return false, model.ErrCredentialsLocked
+	}
+
+	step, ok, err := t.matchCode(user.UserID, *factor, code, currentTime)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		factor.LastUsedStep = step
+	} else {
+		i := slices.Index(factor.RecoveryCodeHashes, hashRecoveryCode(code))
+		if i != -1 {
+			ok = true
+			factor.RecoveryCodeHashes = slices.Delete(factor.RecoveryCodeHashes, i, i+1)
+		}
+	}
+	if ok {
+		factor.FailedAttempts = 0
+		factor.LockedUntil = nil
+		return true, nil
+	}
+
+	factor.FailedAttempts++
+	if factor.FailedAttempts >= t.lockout.MaxFailedAttempts {
+		lockedUntil := currentTime.Add(t.lockout.Duration)
+		factor.LockedUntil = &lockedUntil
+		factor.FailedAttempts = 0
+	}
+	return false, nil
+}
+
+func (t totpService) findUser(userID uuid.UUID) (*model.User, error) {
+	user, err := t.userRepository.Find(model.FindSpec{
+		TenantID: t.tenantID,
+		UserID:   &userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == model.Deleted {
+		return nil, model.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// matchCode returns step of code matching secret within skew, steps up to last used one are not matched
+func (t totpService) matchCode(userID uuid.UUID, factor model.TOTPFactor, code string, currentTime time.Time) (int64, bool, error) {
+	secret, err := t.cipher.Decrypt(factor.EncryptedSecret, userID[:])
+	if err != nil {
+		return 0, false, err
+	}
+	currentStep := currentTime.Unix() / totpPeriod
+	for step := currentStep - totpSkew; step <= currentStep+totpSkew; step++ {
+		if step <= factor.LastUsedStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(secret, step)), []byte(code)) == 1 {
+			return step, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+func (t totpService) uri(login, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", t.issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", strconv.Itoa(totpDigits))
+	query.Set("period", strconv.Itoa(totpPeriod))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + t.issuer + ":" + login,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// totpCode is HOTP of RFC 4226 for time step
+func totpCode(secret []byte, step int64) string {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step)) // nolint:gosec
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%totpModulo)
+}
+
+func newRecoveryCode() (string, error) {
+	b := make([]byte, recoveryCodeLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))[:recoveryCodeLength]
+	return code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:], nil
+}
+
+// hashRecoveryCode hashes recovery code ignoring case and separators, codes are random so hash is not salted
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	hash := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(hash[:])
+}
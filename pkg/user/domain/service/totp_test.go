@@ -0,0 +1,90 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"userservice/pkg/user/domain/model"
+)
+
+// rfc6238Secret is SHA-1 secret of test vectors of RFC 6238, appendix B
+var rfc6238Secret = []byte("12345678901234567890")
+
+func TestTOTPCode(t *testing.T) {
+	// codes are the last six digits of eight digit codes of RFC 6238
+	tests := []struct {
+		unixTime int64
+		code     string
+	}{
+		{unixTime: 59, code: "287082"},
+		{unixTime: 1111111109, code: "081804"},
+		{unixTime: 1111111111, code: "050471"},
+		{unixTime: 1234567890, code: "005924"},
+		{unixTime: 2000000000, code: "279037"},
+		{unixTime: 20000000000, code: "353130"},
+	}
+	for _, tt := range tests {
+		t.Run(time.Unix(tt.unixTime, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			if code := totpCode(rfc6238Secret, tt.unixTime/totpPeriod); code != tt.code {
+				t.Errorf("totpCode() = %q, want %q", code, tt.code)
+			}
+		})
+	}
+}
+
+func TestMatchCode(t *testing.T) {
+	cipher, err := NewAESSecretCipher(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := uuid.New()
+	encryptedSecret, err := cipher.Encrypt(rfc6238Secret, userID[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := totpService{cipher: cipher}
+
+	currentTime := time.Unix(1234567890, 0)
+	currentStep := currentTime.Unix() / totpPeriod
+	tests := []struct {
+		name         string
+		lastUsedStep int64
+		code         string
+		step         int64
+		match        bool
+	}{
+		{name: "current step", code: totpCode(rfc6238Secret, currentStep), step: currentStep, match: true},
+		{name: "previous step within skew", code: totpCode(rfc6238Secret, currentStep-totpSkew), step: currentStep - totpSkew, match: true},
+		{name: "next step within skew", code: totpCode(rfc6238Secret, currentStep+totpSkew), step: currentStep + totpSkew, match: true},
+		{name: "step out of skew", code: totpCode(rfc6238Secret, currentStep-totpSkew-1)},
+		{name: "wrong code", code: "000000"},
+		{name: "replayed step", lastUsedStep: currentStep, code: totpCode(rfc6238Secret, currentStep)},
+		{name: "step before last used", lastUsedStep: currentStep, code: totpCode(rfc6238Secret, currentStep-1)},
+		{name: "step after last used", lastUsedStep: currentStep, code: totpCode(rfc6238Secret, currentStep+1), step: currentStep + 1, match: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factor := model.TOTPFactor{
+				EncryptedSecret: encryptedSecret,
+				LastUsedStep:    tt.lastUsedStep,
+			}
+			step, match, err := service.matchCode(userID, factor, tt.code, currentTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if match != tt.match || step != tt.step {
+				t.Errorf("matchCode() = (%d, %v), want (%d, %v)", step, match, tt.step, tt.match)
+			}
+		})
+	}
+
+	t.Run("secret of other user", func(t *testing.T) {
+		factor := model.TOTPFactor{EncryptedSecret: encryptedSecret}
+		_, _, err := service.matchCode(uuid.New(), factor, totpCode(rfc6238Secret, currentStep), currentTime)
+		if err == nil {
+			t.Error("matchCode() error = nil, want error of decryption")
+		}
+	})
+}
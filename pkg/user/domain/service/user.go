@@ -2,7 +2,7 @@ package service
 
 import (
 	"errors"
-	"reflect"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,31 +12,56 @@ import (
 )
 
 type UserService interface {
-	CreateUser(login string) (uuid.UUID, error)
-	UpdateUserStatus(userID uuid.UUID, status model.UserStatus) error
-	UpdateUserEmail(userID uuid.UUID, email *string) error
-	UpdateUserTelegram(userID uuid.UUID, telegram *string) error
+	CreateUser(login string, profile model.Profile, contacts []model.Contact) (uuid.UUID, error)
+	UpdateUserStatus(userID uuid.UUID, status model.UserStatus, source model.StatusSource, expiresAt *time.Time) error
+	ExpireUserStatus(userID uuid.UUID, expiresAt time.Time) error
+	ClearStatusOverride(userID uuid.UUID) error
+	UpdateUser(userID uuid.UUID, profile model.Profile, contacts []model.Contact) error
+	UpdateUserAttributes(userID uuid.UUID, attributes []model.Attribute, labels []string) error
+	// UpdateNotificationPreferences replaces notification preferences of user with full set of preferences
+	UpdateNotificationPreferences(userID uuid.UUID, preferences []model.NotificationPreference) error
+	// VerifyContact and ResendContactVerification find contact by canonical form of value.
+	// VerifyContact returns false when code is wrong, failed attempt is stored then and has to be committed
+	VerifyContact(userID uuid.UUID, contactType model.ContactType, canonical, code string) (bool, error)
+	ResendContactVerification(userID uuid.UUID, contactType model.ContactType, canonical string) error
 	DeleteUser(userID uuid.UUID, hard bool) error
+	RestoreUser(userID uuid.UUID) error
+	// MergeUsers reports organizationIDs in event, memberships of source in them are transferred
+	// by OrganizationService.TransferUserMemberships within the same transaction
+	MergeUsers(sourceID, targetID uuid.UUID, organizationIDs []uuid.UUID) error
+	AssignRole(userID uuid.UUID, role model.Role) error
+	RevokeRole(userID uuid.UUID, role string) error
 }
 
 func NewUserService(
 	userRepository model.UserRepository,
 	eventDispatcher domain.EventDispatcher,
+	tenantID *string,
 ) UserService {
 	return &userService{
+		tenantID:        tenantID,
 		userRepository:  userRepository,
 		eventDispatcher: eventDispatcher,
 	}
 }
 
 type userService struct {
+	// tenantID restricts users found by service, it is nil when service acts on users of all tenants
+	tenantID        *string
 	userRepository  model.UserRepository
 	eventDispatcher domain.EventDispatcher
 }
 
-func (u userService) CreateUser(login string) (uuid.UUID, error) {
+// CreateUser keeps login in the form it was given, uniqueness is checked against canonical form within tenant of service.
+// Created user with its contacts is described by single UserCreated event
+func (u userService) CreateUser(login string, profile model.Profile, contacts []model.Contact) (uuid.UUID, error) {
+	if u.tenantID == nil {
+		return uuid.Nil, model.ErrInvalidTenant
+	}
+	canonicalLogin := model.CanonicalLogin(login)
 	_, err := u.userRepository.Find(model.FindSpec{
-		Login: &login,
+		TenantID: u.tenantID,
+		Login:    &canonicalLogin,
 	})
 	if err != nil && !errors.Is(err, model.ErrUserNotFound) {
 		return uuid.Nil, err
@@ -45,203 +70,818 @@ func (u userService) CreateUser(login string) (uuid.UUID, error) {
 		return uuid.Nil, model.ErrUserLoginAlreadyUsed
 	}
 
+	contacts, err = resolveContacts(contacts)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	contacts = keepVerifiedAt(nil, contacts)
 	userID, err := u.userRepository.NextID()
 	if err != nil {
 		return uuid.Nil, err
 	}
+	err = u.checkContactsUnused(*u.tenantID, userID, contacts)
+	if err != nil {
+		return uuid.Nil, err
+	}
 
-	status := model.Blocked
+	status := model.Pending
 	currentTime := time.Now()
-	err = u.userRepository.Store(model.User{
+	user := model.User{
 		UserID:    userID,
+		TenantID:  *u.tenantID,
 		Status:    status,
 		Login:     login,
+		Profile:   profile,
+		Version:   1,
 		CreatedAt: currentTime,
 		UpdatedAt: currentTime,
-	})
+	}
+	verifications, verificationEvents, err := requestVerifications(user, contacts, currentTime)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	user.Contacts = contacts
+	user.ContactVerifications = verifications
+	err = u.userRepository.Store(user)
 	if err != nil {
 		return uuid.Nil, err
 	}
 
-	return userID, u.eventDispatcher.Dispatch(&model.UserCreated{
+	err = u.eventDispatcher.Dispatch(&model.UserCreated{
 		UserID:    userID,
+		TenantID:  user.TenantID,
 		Status:    status,
 		Login:     login,
+		Profile:   profile,
+		Contacts:  contacts,
+		Version:   user.Version,
 		CreatedAt: currentTime,
 	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return userID, u.dispatchVerifications(user.Version, verificationEvents)
 }
 
-func (u userService) UpdateUserStatus(userID uuid.UUID, status model.UserStatus) error {
+// UpdateUserStatus changes user status, status with expiresAt is temporary and is reverted by ExpireUserStatus.
+// System can not change status set manually until override is cleared by ClearStatusOverride
+func (u userService) UpdateUserStatus(userID uuid.UUID, status model.UserStatus, source model.StatusSource, expiresAt *time.Time) error {
+	currentTime := time.Now()
+	if expiresAt != nil && (!status.CanExpire() || !expiresAt.After(currentTime)) {
+		return model.ErrInvalidStatusExpiration
+	}
+
 	user, err := u.userRepository.Find(model.FindSpec{
-		UserID: &userID,
+		TenantID: u.tenantID,
+		UserID:   &userID,
 	})
 	if err != nil {
 		return err
 	}
 
-	if user.Status == status {
+	var currentExpiresAt *time.Time
+	if user.StatusExpiration != nil {
+		currentExpiresAt = &user.StatusExpiration.ExpiresAt
+	}
+	if source == model.StatusSourceSystem && user.StatusSource == model.StatusSourceManual {
+		return model.ErrUserStatusOverridden
+	}
+	if user.Status == status && user.StatusSource == source && equalTime(currentExpiresAt, expiresAt) {
+		return nil
+	}
+	if user.Status != status && !user.Status.CanTransitTo(status) {
+		return model.ErrInvalidUserStatusTransition
+	}
+
+	event := &model.UserUpdated{
+		UserID:    userID,
+		TenantID:  user.TenantID,
+		UpdatedAt: currentTime,
+	}
+	if user.Status != status || user.StatusSource != source || expiresAt != nil {
+		event.UpdatedFields = &model.UpdatedFields{
+			StatusExpiresAt: expiresAt,
+		}
+		if user.Status != status {
+			event.UpdatedFields.Status = &status
+		}
+		if user.StatusSource != source {
+			event.UpdatedFields.StatusSource = &source
+		}
+	}
+	if currentExpiresAt != nil && expiresAt == nil {
+		event.RemovedFields = &model.RemovedFields{
+			StatusExpiresAt: true,
+		}
+	}
+	event.PreviousFields = previousFields(*user, event.UpdatedFields, event.RemovedFields)
+
+	if expiresAt != nil {
+		restoreStatus, restoreStatusSource := user.Status, user.StatusSource
+		if user.StatusExpiration != nil {
+			// prolongation of temporary status keeps status user had before it
+			restoreStatus, restoreStatusSource = user.StatusExpiration.RestoreStatus, user.StatusExpiration.RestoreStatusSource
+		}
+		user.StatusExpiration = &model.StatusExpiration{
+			ExpiresAt:           *expiresAt,
+			RestoreStatus:       restoreStatus,
+			RestoreStatusSource: restoreStatusSource,
+		}
+	} else {
+		user.StatusExpiration = nil
+	}
+	user.Status = status
+	user.StatusSource = source
+	user.UpdatedAt = currentTime
+	user.Version++
+	err = u.userRepository.Store(*user)
+	if err != nil {
+		return err
+	}
+
+	event.Version = user.Version
+	return u.eventDispatcher.Dispatch(event)
+}
+
+// ExpireUserStatus restores status user had before temporary status,
+// does nothing when temporary status expiring at expiresAt was already replaced or user was deleted
+func (u userService) ExpireUserStatus(userID uuid.UUID, expiresAt time.Time) error {
+	user, err := u.userRepository.Find(model.FindSpec{
+		TenantID: u.tenantID,
+		UserID:   &userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if user.Status == model.Deleted || user.StatusExpiration == nil || !user.StatusExpiration.ExpiresAt.Equal(expiresAt) {
 		return nil
 	}
 
 	currentTime := time.Now()
+	status, source := user.StatusExpiration.RestoreStatus, user.StatusExpiration.RestoreStatusSource
+	// previous status that can not be reached from current one is not restored, temporary status becomes permanent
+	if user.Status != status && !user.Status.CanTransitTo(status) {
+		status, source = user.Status, user.StatusSource
+	}
+	updatedFields := &model.UpdatedFields{
+		Status: &status,
+	}
+	if user.StatusSource != source {
+		updatedFields.StatusSource = &source
+	}
+	removedFields := &model.RemovedFields{
+		StatusExpiresAt: true,
+	}
+	event := &model.UserUpdated{
+		UserID:         userID,
+		TenantID:       user.TenantID,
+		UpdatedAt:      currentTime,
+		UpdatedFields:  updatedFields,
+		RemovedFields:  removedFields,
+		PreviousFields: previousFields(*user, updatedFields, removedFields),
+	}
+
 	user.Status = status
+	user.StatusSource = source
+	user.StatusExpiration = nil
 	user.UpdatedAt = currentTime
+	user.Version++
 	err = u.userRepository.Store(*user)
 	if err != nil {
 		return err
 	}
 
-	return u.eventDispatcher.Dispatch(&model.UserUpdated{
+	event.Version = user.Version
+	return u.eventDispatcher.Dispatch(event)
+}
+
+// ClearStatusOverride returns status under control of the system, temporary status becomes permanent
+func (u userService) ClearStatusOverride(userID uuid.UUID) error {
+	user, err := u.userRepository.Find(model.FindSpec{
+		TenantID: u.tenantID,
+		UserID:   &userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if user.StatusSource == model.StatusSourceSystem {
+		return nil
+	}
+
+	currentTime := time.Now()
+	source := model.StatusSourceSystem
+	event := &model.UserUpdated{
 		UserID:    userID,
+		TenantID:  user.TenantID,
 		UpdatedAt: currentTime,
-		UpdatedFields: &struct {
-			Status   *model.UserStatus
-			Email    *string
-			Telegram *string
-		}{Status: &status},
-	})
+		UpdatedFields: &model.UpdatedFields{
+			StatusSource: &source,
+		},
+	}
+	if user.StatusExpiration != nil {
+		event.RemovedFields = &model.RemovedFields{
+			StatusExpiresAt: true,
+		}
+	}
+	event.PreviousFields = previousFields(*user, event.UpdatedFields, event.RemovedFields)
+
+	user.StatusSource = source
+	user.StatusExpiration = nil
+	user.UpdatedAt = currentTime
+	user.Version++
+	err = u.userRepository.Store(*user)
+	if err != nil {
+		return err
+	}
+
+	event.Version = user.Version
+	return u.eventDispatcher.Dispatch(event)
 }
 
-// nolint:dupl
-func (u userService) UpdateUserEmail(userID uuid.UUID, email *string) error {
+// UpdateUser replaces profile and contacts of user, all changes are described by single UserUpdated event
+func (u userService) UpdateUser(userID uuid.UUID, profile model.Profile, contacts []model.Contact) error {
 	user, err := u.userRepository.Find(model.FindSpec{
-		UserID: &userID,
+		TenantID: u.tenantID,
+		UserID:   &userID,
 	})
 	if err != nil {
 		return err
 	}
-	if reflect.DeepEqual(user.Email, email) {
+
+	contacts, err = resolveContacts(contacts)
+	if err != nil {
+		return err
+	}
+	contacts = keepVerifiedAt(user.Contacts, contacts)
+	updatedContacts, removedContacts := diffContacts(user.Contacts, contacts)
+	updated, removed := diffProfile(user.Profile, profile)
+	if updated == nil && removed == nil && len(updatedContacts) == 0 && len(removedContacts) == 0 {
 		return nil
 	}
-
-	if email != nil {
-		userWithEmail, err := u.userRepository.Find(model.FindSpec{
-			Email: email,
-		})
-		if err != nil && !errors.Is(err, model.ErrUserNotFound) {
-			return err
+	if len(updatedContacts) > 0 {
+		if updated == nil {
+			updated = &model.UpdatedFields{}
 		}
-		if userWithEmail != nil && userWithEmail.UserID != user.UserID {
-			return model.ErrUserEmailAlreadyUsed
+		updated.Contacts = updatedContacts
+	}
+	if len(removedContacts) > 0 {
+		if removed == nil {
+			removed = &model.RemovedFields{}
 		}
+		removed.Contacts = removedContacts
+	}
+
+	err = u.checkContactsUnused(user.TenantID, userID, updatedContacts)
+	if err != nil {
+		return err
 	}
 
 	currentTime := time.Now()
-	user.Email = email
+	verifications, verificationEvents, err := requestVerifications(*user, contacts, currentTime)
+	if err != nil {
+		return err
+	}
+	event := &model.UserUpdated{
+		UserID:         userID,
+		TenantID:       user.TenantID,
+		UpdatedFields:  updated,
+		RemovedFields:  removed,
+		PreviousFields: previousFields(*user, updated, removed),
+		UpdatedAt:      currentTime,
+	}
+
+	user.Profile = profile
+	user.Contacts = contacts
+	user.ContactVerifications = verifications
 	user.UpdatedAt = currentTime
+	user.Version++
 	err = u.userRepository.Store(*user)
 	if err != nil {
 		return err
 	}
 
-	if email == nil {
-		return u.eventDispatcher.Dispatch(&model.UserUpdated{
-			UserID:    userID,
-			UpdatedAt: currentTime,
-			RemovedFields: &struct {
-				Email    *bool
-				Telegram *bool
-			}{Email: toPtr(true)},
-		})
+	event.Version = user.Version
+	err = u.eventDispatcher.Dispatch(event)
+	if err != nil {
+		return err
 	}
-
-	return u.eventDispatcher.Dispatch(&model.UserUpdated{
-		UserID:    userID,
-		UpdatedAt: currentTime,
-		UpdatedFields: &struct {
-			Status   *model.UserStatus
-			Email    *string
-			Telegram *string
-		}{Email: email},
-	})
+	return u.dispatchVerifications(user.Version, verificationEvents)
 }
 
-// nolint:dupl
-func (u userService) UpdateUserTelegram(userID uuid.UUID, telegram *string) error {
+func (u userService) UpdateUserAttributes(userID uuid.UUID, attributes []model.Attribute, labels []string) error {
 	user, err := u.userRepository.Find(model.FindSpec{
-		UserID: &userID,
+		TenantID: u.tenantID,
+		UserID:   &userID,
 	})
 	if err != nil {
 		return err
 	}
-	if reflect.DeepEqual(user.Telegram, telegram) {
+
+	labels = uniqueSorted(labels)
+	updatedAttributes, removedAttributes := diffAttributes(user.Attributes, attributes)
+	addedLabels, removedLabels := diffLabels(user.Labels, labels)
+
+	event := &model.UserUpdated{
+		UserID:   userID,
+		TenantID: user.TenantID,
+	}
+	if len(updatedAttributes) > 0 || len(addedLabels) > 0 {
+		event.UpdatedFields = &model.UpdatedFields{
+			Attributes: updatedAttributes,
+			Labels:     addedLabels,
+		}
+	}
+	if len(removedAttributes) > 0 || len(removedLabels) > 0 {
+		event.RemovedFields = &model.RemovedFields{
+			Attributes: removedAttributes,
+			Labels:     removedLabels,
+		}
+	}
+	if event.UpdatedFields == nil && event.RemovedFields == nil {
 		return nil
 	}
+	event.PreviousFields = previousFields(*user, event.UpdatedFields, event.RemovedFields)
 
-	if telegram != nil {
-		userWithTelegram, err := u.userRepository.Find(model.FindSpec{
-			Telegram: telegram,
-		})
-		if err != nil && !errors.Is(err, model.ErrUserNotFound) {
-			return err
+	currentTime := time.Now()
+	user.Attributes = attributes
+	user.Labels = labels
+	user.UpdatedAt = currentTime
+	user.Version++
+	err = u.userRepository.Store(*user)
+	if err != nil {
+		return err
+	}
+
+	event.UpdatedAt = currentTime
+	event.Version = user.Version
+	return u.eventDispatcher.Dispatch(event)
+}
+
+func (u userService) UpdateNotificationPreferences(userID uuid.UUID, preferences []model.NotificationPreference) error {
+	preferences, err := sortNotificationPreferences(preferences)
+	if err != nil {
+		return err
+	}
+	user, err := u.userRepository.Find(model.FindSpec{
+		TenantID: u.tenantID,
+		UserID:   &userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	updated, removed := diffNotificationPreferences(user.NotificationPreferences, preferences)
+	event := &model.UserUpdated{
+		UserID:   userID,
+		TenantID: user.TenantID,
+	}
+	if len(updated) > 0 {
+		event.UpdatedFields = &model.UpdatedFields{
+			NotificationPreferences: updated,
 		}
-		if userWithTelegram != nil && userWithTelegram.UserID != user.UserID {
-			return model.ErrUserTelegramAlreadyUsed
+	}
+	if len(removed) > 0 {
+		event.RemovedFields = &model.RemovedFields{
+			NotificationPreferences: removed,
 		}
 	}
+	if event.UpdatedFields == nil && event.RemovedFields == nil {
+		return nil
+	}
+	event.PreviousFields = previousFields(*user, event.UpdatedFields, event.RemovedFields)
 
 	currentTime := time.Now()
-	user.Telegram = telegram
+	user.NotificationPreferences = preferences
 	user.UpdatedAt = currentTime
+	user.Version++
 	err = u.userRepository.Store(*user)
 	if err != nil {
 		return err
 	}
 
-	if telegram == nil {
-		return u.eventDispatcher.Dispatch(&model.UserUpdated{
-			UserID:    userID,
-			UpdatedAt: currentTime,
-			RemovedFields: &struct {
-				Email    *bool
-				Telegram *bool
-			}{Telegram: toPtr(true)},
-		})
+	event.UpdatedAt = currentTime
+	event.Version = user.Version
+	return u.eventDispatcher.Dispatch(event)
+}
+
+func (u userService) VerifyContact(userID uuid.UUID, contactType model.ContactType, canonical, code string) (bool, error) {
+	user, err := u.userRepository.Find(model.FindSpec{
+		TenantID: u.tenantID,
+		UserID:   &userID,
+	})
+	if err != nil {
+		return false, err
 	}
 
-	return u.eventDispatcher.Dispatch(&model.UserUpdated{
+	contact := findContact(user.Contacts, contactType, canonical)
+	if contact == nil {
+		return false, model.ErrUserContactNotFound
+	}
+	if contact.Verified() {
+		return false, model.ErrUserContactAlreadyVerified
+	}
+
+	currentTime := time.Now()
+	verificationIndex := findContactVerification(user.ContactVerifications, contactType, canonical)
+	if verificationIndex < 0 {
+		return false, model.ErrContactVerificationNotFound
+	}
+	verification := &user.ContactVerifications[verificationIndex]
+	err = checkVerificationCode(*verification, code, currentTime)
+	if errors.Is(err, model.ErrInvalidContactVerificationCode) {
+		// counters of attempts are not changes of user, so neither version nor update time is changed
+		verification.FailedAttempts++
+		if verification.FailedAttempts >= verificationMaxFailedAttempts {
+			user.ContactVerifications = slices.Delete(user.ContactVerifications, verificationIndex, verificationIndex+1)
+		}
+		return false, u.userRepository.Store(*user)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	previous := previousFields(*user, &model.UpdatedFields{Contacts: []model.Contact{*contact}}, nil)
+	contact.VerifiedAt = &currentTime
+	user.ContactVerifications = slices.Delete(user.ContactVerifications, verificationIndex, verificationIndex+1)
+	user.UpdatedAt = currentTime
+	user.Version++
+	err = u.userRepository.Store(*user)
+	if err != nil {
+		return false, err
+	}
+
+	return true, u.eventDispatcher.Dispatch(&model.UserUpdated{
 		UserID:    userID,
+		TenantID:  user.TenantID,
 		UpdatedAt: currentTime,
-		UpdatedFields: &struct {
-			Status   *model.UserStatus
-			Email    *string
-			Telegram *string
-		}{Telegram: telegram},
+		UpdatedFields: &model.UpdatedFields{
+			Contacts: []model.Contact{*contact},
+		},
+		PreviousFields: previous,
+		Version:        user.Version,
+	})
+}
+
+func (u userService) ResendContactVerification(userID uuid.UUID, contactType model.ContactType, canonical string) error {
+	user, err := u.userRepository.Find(model.FindSpec{
+		TenantID: u.tenantID,
+		UserID:   &userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	contact := findContact(user.Contacts, contactType, canonical)
+	if contact == nil {
+		return model.ErrUserContactNotFound
+	}
+	if contact.Verified() {
+		return model.ErrUserContactAlreadyVerified
+	}
+
+	currentTime := time.Now()
+	i := findContactVerification(user.ContactVerifications, contactType, canonical)
+	if i >= 0 && !verificationResendAllowed(user.ContactVerifications[i], currentTime) {
+		return model.ErrContactVerificationResent
+	}
+	verification, code, err := newContactVerification(*contact, currentTime)
+	if err != nil {
+		return err
+	}
+	if i >= 0 {
+		user.ContactVerifications[i] = verification
+	} else {
+		user.ContactVerifications = append(user.ContactVerifications, verification)
+	}
+	// new code is not a change of user, so neither version nor update time is changed
+	err = u.userRepository.Store(*user)
+	if err != nil {
+		return err
+	}
+
+	return u.eventDispatcher.Dispatch(&model.ContactVerificationRequested{
+		UserID:    userID,
+		TenantID:  user.TenantID,
+		Contact:   *contact,
+		Code:      code,
+		ExpiresAt: verification.ExpiresAt,
+		Version:   user.Version,
 	})
 }
 
 func (u userService) DeleteUser(userID uuid.UUID, hard bool) error {
 	user, err := u.userRepository.Find(model.FindSpec{
-		UserID: &userID,
+		TenantID: u.tenantID,
+		UserID:   &userID,
 	})
 	if err != nil {
 		return err
 	}
 
+	currentTime := time.Now()
+	user.Status = model.Deleted
+	user.StatusExpiration = nil
+	user.UpdatedAt = currentTime
+	user.Version++
+	user.DeletedAt = &currentTime
+	// hard deleted user is not stored again, the event is the last trace of user
 	if hard {
 		err = u.userRepository.HardDelete(userID)
+	} else {
+		err = u.userRepository.Store(*user)
+	}
+	if err != nil {
+		return err
+	}
+
+	return u.eventDispatcher.Dispatch(&model.UserDeleted{
+		UserID:    userID,
+		TenantID:  user.TenantID,
+		Status:    model.Deleted,
+		DeletedAt: currentTime,
+		Hard:      hard,
+		Version:   user.Version,
+	})
+}
+
+func (u userService) RestoreUser(userID uuid.UUID) error {
+	user, err := u.userRepository.Find(model.FindSpec{
+		TenantID: u.tenantID,
+		UserID:   &userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if user.Status != model.Deleted {
+		return model.ErrUserNotDeleted
+	}
+	if user.MergedInto != nil {
+		return model.ErrUserMerged
+	}
+
+	status := model.Blocked
+	currentTime := time.Now()
+	user.Status = status
+	user.UpdatedAt = currentTime
+	user.Version++
+	user.DeletedAt = nil
+	err = u.userRepository.Store(*user)
+	if err != nil {
+		return err
+	}
+
+	return u.eventDispatcher.Dispatch(&model.UserRestored{
+		UserID:     userID,
+		TenantID:   user.TenantID,
+		Status:     status,
+		RestoredAt: currentTime,
+		Version:    user.Version,
+	})
+}
+
+// MergeUsers moves contacts with their pending verifications, attributes, labels, notification preferences, roles
+// and linked identities of source user to target user and deletes source user.
+// Target keeps its primary contacts, values of attributes and preferences it already has
+func (u userService) MergeUsers(sourceID, targetID uuid.UUID, organizationIDs []uuid.UUID) error {
+	if sourceID == targetID {
+		return model.ErrMergeSameUser
+	}
+	source, err := u.userRepository.Find(model.FindSpec{
+		TenantID: u.tenantID,
+		UserID:   &sourceID,
+	})
+	if err != nil {
+		return err
+	}
+	target, err := u.userRepository.Find(model.FindSpec{
+		TenantID: u.tenantID,
+		UserID:   &targetID,
+	})
+	if err != nil {
+		return err
+	}
+	// users of different tenants are not visible to each other, contacts and identities are unique only within tenant
+	if source.TenantID != target.TenantID {
+		return model.ErrUserNotFound
+	}
+	if source.Status == model.Deleted || target.Status == model.Deleted {
+		return model.ErrMergeDeletedUser
+	}
+
+	var movedContacts []model.Contact
+	for _, contact := range source.Contacts {
+		if findContact(target.Contacts, contact.Type, contact.Canonical) != nil {
+			continue
+		}
+		contact.Primary = false
+		movedContacts = append(movedContacts, contact)
+	}
+	contacts, err := resolveContacts(append(slices.Clone(target.Contacts), movedContacts...))
+	if err != nil {
+		return err
+	}
+	verifications := slices.Clone(target.ContactVerifications)
+	for _, verification := range source.ContactVerifications {
+		if findContact(movedContacts, verification.Type, verification.Value) != nil {
+			verifications = append(verifications, verification)
+		}
+	}
+	var movedAttributes []model.Attribute
+	for _, attribute := range source.Attributes {
+		if !slices.ContainsFunc(target.Attributes, func(a model.Attribute) bool { return a.Key == attribute.Key }) {
+			movedAttributes = append(movedAttributes, attribute)
+		}
+	}
+	addedLabels, _ := diffLabels(target.Labels, uniqueSorted(slices.Concat(target.Labels, source.Labels)))
+	var movedPreferences []model.NotificationPreference
+	for _, preference := range source.NotificationPreferences {
+		if findNotificationPreference(target.NotificationPreferences, preference) == nil {
+			movedPreferences = append(movedPreferences, preference)
+		}
+	}
+	preferences, err := sortNotificationPreferences(slices.Concat(target.NotificationPreferences, movedPreferences))
+	if err != nil {
+		return err
+	}
+	movedIdentities := source.Identities
+	updatedContacts, _ := diffContacts(target.Contacts, contacts)
+	sourceLabels := source.Labels
+	sourcePreferences := source.NotificationPreferences
+	sourceRoles := source.Roles
+
+	// source is stored first to release its contacts
+	currentTime := time.Now()
+	source.Status = model.Deleted
+	source.StatusExpiration = nil
+	source.Contacts = nil
+	source.ContactVerifications = nil
+	source.Attributes = nil
+	source.Labels = nil
+	source.NotificationPreferences = nil
+	source.Roles = nil
+	source.Identities = nil
+	source.UpdatedAt = currentTime
+	source.Version++
+	source.DeletedAt = &currentTime
+	source.MergedInto = &targetID
+	err = u.userRepository.Store(*source)
+	if err != nil {
+		return err
+	}
+
+	targetUpdatedFields := &model.UpdatedFields{
+		Contacts:                updatedContacts,
+		Attributes:              movedAttributes,
+		Labels:                  addedLabels,
+		NotificationPreferences: movedPreferences,
+	}
+	previous := previousFields(*target, targetUpdatedFields, nil)
+	target.Contacts = contacts
+	target.ContactVerifications = verifications
+	target.Attributes = append(target.Attributes, movedAttributes...)
+	target.Labels = uniqueSorted(slices.Concat(target.Labels, addedLabels))
+	target.NotificationPreferences = preferences
+	target.Roles = uniqueSorted(slices.Concat(target.Roles, sourceRoles))
+	target.Identities = append(target.Identities, movedIdentities...)
+	target.UpdatedAt = currentTime
+	target.Version++
+	err = u.userRepository.Store(*target)
+	if err != nil {
+		return err
+	}
+
+	if len(updatedContacts) > 0 || len(movedAttributes) > 0 || len(addedLabels) > 0 || len(movedPreferences) > 0 {
+		err = u.eventDispatcher.Dispatch(&model.UserUpdated{
+			UserID:         targetID,
+			TenantID:       target.TenantID,
+			UpdatedAt:      currentTime,
+			UpdatedFields:  targetUpdatedFields,
+			PreviousFields: previous,
+			Version:        target.Version,
+		})
 		if err != nil {
 			return err
 		}
 	}
+	return u.eventDispatcher.Dispatch(&model.UserMerged{
+		SourceUserID:            sourceID,
+		TargetUserID:            targetID,
+		TenantID:                target.TenantID,
+		MergedAt:                currentTime,
+		SourceVersion:           source.Version,
+		TargetVersion:           target.Version,
+		Labels:                  sourceLabels,
+		NotificationPreferences: sourcePreferences,
+		Roles:                   sourceRoles,
+		OrganizationIDs:         organizationIDs,
+	})
+}
+
+func (u userService) AssignRole(userID uuid.UUID, role model.Role) error {
+	user, err := u.userRepository.Find(model.FindSpec{
+		TenantID: u.tenantID,
+		UserID:   &userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(user.Roles, role.Name) {
+		return nil
+	}
 
 	currentTime := time.Now()
-	user.Status = model.Deleted
+	user.Roles = uniqueSorted(append(user.Roles, role.Name))
 	user.UpdatedAt = currentTime
-	user.DeletedAt = &currentTime
+	user.Version++
 	err = u.userRepository.Store(*user)
 	if err != nil {
 		return err
 	}
 
-	return u.eventDispatcher.Dispatch(&model.UserDeleted{
+	return u.eventDispatcher.Dispatch(&model.UserRoleAssigned{
+		UserID:     userID,
+		TenantID:   user.TenantID,
+		Role:       role.Name,
+		AssignedAt: currentTime,
+		Version:    user.Version,
+	})
+}
+
+func (u userService) RevokeRole(userID uuid.UUID, role string) error {
+	user, err := u.userRepository.Find(model.FindSpec{
+		TenantID: u.tenantID,
+		UserID:   &userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(user.Roles, role) {
+		return nil
+	}
+
+	currentTime := time.Now()
+	user.Roles = slices.DeleteFunc(user.Roles, func(r string) bool {
+		return r == role
+	})
+	user.UpdatedAt = currentTime
+	user.Version++
+	err = u.userRepository.Store(*user)
+	if err != nil {
+		return err
+	}
+
+	return u.eventDispatcher.Dispatch(&model.UserRoleRevoked{
 		UserID:    userID,
-		Status:    model.Deleted,
-		DeletedAt: currentTime,
-		Hard:      hard,
+		TenantID:  user.TenantID,
+		Role:      role,
+		RevokedAt: currentTime,
+		Version:   user.Version,
 	})
 }
 
-func toPtr[T any](v T) *T {
-	return &v
+// checkContactsUnused fails when any of contacts belongs to user of tenant other than userID
+func (u userService) checkContactsUnused(tenantID string, userID uuid.UUID, contacts []model.Contact) error {
+	for _, contact := range contacts {
+		userWithContact, err := u.userRepository.Find(model.FindSpec{
+			TenantID: &tenantID,
+			Contact:  &model.ContactSpec{Type: contact.Type, Value: contact.Canonical},
+		})
+		if err != nil && !errors.Is(err, model.ErrUserNotFound) {
+			return err
+		}
+		if userWithContact != nil && userWithContact.UserID != userID {
+			return model.ErrUserContactAlreadyUsed
+		}
+	}
+	return nil
+}
+
+func (u userService) dispatchVerifications(version int64, events []model.ContactVerificationRequested) error {
+	for _, event := range events {
+		event.Version = version
+		err := u.eventDispatcher.Dispatch(&event)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
 }
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"userservice/pkg/user/domain/model"
+)
+
+const (
+	verificationCodeLength = 6
+	verificationCodeTTL    = time.Hour
+	// verificationMaxFailedAttempts limits guessing of code, new code has to be requested once they are used up
+	verificationMaxFailedAttempts = 5
+	// verificationResendInterval is the least time between codes sent to the same contact
+	verificationResendInterval = time.Minute
+)
+
+// newContactVerification generates one-time numeric code, code is returned to be delivered and only its hash is kept
+func newContactVerification(contact model.Contact, currentTime time.Time) (model.ContactVerification, string, error) {
+	code := make([]byte, verificationCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return model.ContactVerification{}, "", errors.WithStack(err)
+		}
+		code[i] = byte('0' + n.Int64())
+	}
+
+	return model.ContactVerification{
+		Type:      contact.Type,
+		Value:     contact.Canonical,
+		CodeHash:  hashVerificationCode(string(code)),
+		ExpiresAt: currentTime.Add(verificationCodeTTL),
+	}, string(code), nil
+}
+
+// requestVerifications keeps pending verifications of remaining contacts and starts verification of contacts user did not have
+func requestVerifications(
+	user model.User,
+	contacts []model.Contact,
+	currentTime time.Time,
+) ([]model.ContactVerification, []model.ContactVerificationRequested, error) {
+	verifications := make([]model.ContactVerification, 0, len(user.ContactVerifications))
+	for _, verification := range user.ContactVerifications {
+		if findContact(contacts, verification.Type, verification.Value) != nil {
+			verifications = append(verifications, verification)
+		}
+	}
+	var events []model.ContactVerificationRequested
+	for _, contact := range contacts {
+		if findContact(user.Contacts, contact.Type, contact.Canonical) != nil {
+			continue
+		}
+		verification, code, err := newContactVerification(contact, currentTime)
+		if err != nil {
+			return nil, nil, err
+		}
+		verifications = append(verifications, verification)
+		events = append(events, model.ContactVerificationRequested{
+			UserID:    user.UserID,
+			TenantID:  user.TenantID,
+			Contact:   contact,
+			Code:      code,
+			ExpiresAt: verification.ExpiresAt,
+		})
+	}
+	return verifications, events, nil
+}
+
+// verificationResendAllowed reports whether new code may be sent, time code was sent is derived from its expiration
+func verificationResendAllowed(verification model.ContactVerification, currentTime time.Time) bool {
+	sentAt := verification.ExpiresAt.Add(-verificationCodeTTL)
+	return !currentTime.Before(sentAt.Add(verificationResendInterval))
+}
+
+func checkVerificationCode(verification model.ContactVerification, code string, currentTime time.Time) error {
+	if !currentTime.Before(verification.ExpiresAt) {
+		return model.ErrContactVerificationExpired
+	}
+	if subtle.ConstantTimeCompare([]byte(verification.CodeHash), []byte(hashVerificationCode(code))) != 1 {
+		return model.ErrInvalidContactVerificationCode
+	}
+	return nil
+}
+
+func hashVerificationCode(code string) string {
+	hash := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(hash[:])
+}
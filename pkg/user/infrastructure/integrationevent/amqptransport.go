@@ -38,6 +38,13 @@ func (t *amqpTransport) Handler() amqp.Handler {
 
 func (t *amqpTransport) handle(ctx context.Context, delivery amqp.Delivery) error {
 	switch delivery.Type {
+	case model.UserCreated{}.Type():
+		var e UserCreated
+		err := json.Unmarshal(delivery.Body, &e)
+		if err != nil {
+			return err
+		}
+		return t.workflowService.RunUserCreatedWorkflow(ctx, delivery.CorrelationID, uuid.MustParse(e.UserID))
 	case model.UserUpdated{}.Type():
 		var e UserUpdated
 		err := json.Unmarshal(delivery.Body, &e)
@@ -46,29 +53,58 @@ func (t *amqpTransport) handle(ctx context.Context, delivery amqp.Delivery) erro
 		}
 		de := model.UserUpdated{
 			UserID:    uuid.MustParse(e.UserID),
+			TenantID:  e.TenantID,
+			Version:   e.Version,
 			UpdatedAt: time.Unix(e.UpdatedAt, 0),
 		}
-		if e.UpdatedFields != nil {
-			de.UpdatedFields = &struct {
-				Status   *model.UserStatus
-				Email    *string
-				Telegram *string
-			}{
-				Status:   (*model.UserStatus)(e.UpdatedFields.Status),
-				Email:    e.UpdatedFields.Email,
-				Telegram: e.UpdatedFields.Telegram,
-			}
+		de.UpdatedFields, err = fromUpdatedFields(e.UpdatedFields)
+		if err != nil {
+			return err
+		}
+		de.PreviousFields, err = fromUpdatedFields(e.PreviousFields)
+		if err != nil {
+			return err
 		}
 		if e.RemovedFields != nil {
-			de.RemovedFields = &struct {
-				Email    *bool
-				Telegram *bool
-			}{
-				Email:    e.RemovedFields.Email,
-				Telegram: e.RemovedFields.Telegram,
+			contacts, err := fromContacts(e.RemovedFields.Contacts)
+			if err != nil {
+				return err
+			}
+			notificationPreferences, err := fromNotificationPreferences(e.RemovedFields.NotificationPreferences)
+			if err != nil {
+				return err
 			}
+			de.RemovedFields = &model.RemovedFields{
+				StatusExpiresAt:         e.RemovedFields.StatusExpiresAt,
+				Contacts:                contacts,
+				DisplayName:             e.RemovedFields.DisplayName,
+				Locale:                  e.RemovedFields.Locale,
+				Timezone:                e.RemovedFields.Timezone,
+				AvatarURL:               e.RemovedFields.AvatarURL,
+				Attributes:              e.RemovedFields.Attributes,
+				Labels:                  e.RemovedFields.Labels,
+				NotificationPreferences: notificationPreferences,
+			}
+		}
+		err = t.workflowService.RunUserUpdatedWorkflow(ctx, delivery.CorrelationID, de)
+		if err != nil {
+			return err
+		}
+		if de.UpdatedFields != nil && de.UpdatedFields.StatusExpiresAt != nil {
+			return t.workflowService.RunUserStatusExpiryWorkflow(ctx, de.UserID, *de.UpdatedFields.StatusExpiresAt)
+		}
+		if de.RemovedFields != nil && de.RemovedFields.StatusExpiresAt {
+			return t.workflowService.CancelUserStatusExpiryWorkflow(ctx, de.UserID)
 		}
-		return t.workflowService.RunUserUpdatedWorkflow(ctx, delivery.CorrelationID, de)
+		return nil
+	case model.UserDeleted{}.Type():
+		var e UserDeleted
+		err := json.Unmarshal(delivery.Body, &e)
+		if err != nil {
+			return err
+		}
+		// temporary status of deleted user never expires
+		return t.workflowService.CancelUserStatusExpiryWorkflow(ctx, uuid.MustParse(e.UserID))
 	default:
 		return errUnhandledDelivery
 	}
@@ -85,7 +121,9 @@ func (t *amqpTransport) withLog(handler amqp.Handler) amqp.Handler {
 			l.Warning(errors.New("invalid content type"), "skipping")
 			return nil
 		}
-		l = l.WithField("body", json.RawMessage(delivery.Body))
+		if !hasSecrets(delivery.Type) {
+			l = l.WithField("body", json.RawMessage(delivery.Body))
+		}
 
 		start := time.Now()
 		err := handler(ctx, delivery)
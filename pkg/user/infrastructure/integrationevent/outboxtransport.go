@@ -2,10 +2,13 @@ package integrationevent
 
 import (
 	"context"
+	"encoding/json"
 
 	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
 	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/amqp"
 	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/outbox"
+
+	"userservice/pkg/user/domain/model"
 )
 
 const (
@@ -15,9 +18,11 @@ const (
 	QueueName        = "user_domain_event"
 	RoutingKeyPrefix = "user."
 	ContentType      = "application/json"
+	// VersionHeader carries version of user in headers of events having version in payload
+	VersionHeader = "version"
 )
 
-func NewOutboxTransport(logger logging.Logger, producer amqp.Producer) outbox.Transport {
+func NewOutboxTransport(logger logging.Logger, producer Producer) outbox.Transport {
 	return &outboxTransport{
 		logger:   logger,
 		producer: producer,
@@ -26,23 +31,42 @@ func NewOutboxTransport(logger logging.Logger, producer amqp.Producer) outbox.Tr
 
 type outboxTransport struct {
 	logger   logging.Logger
-	producer amqp.Producer
+	producer Producer
+}
+
+// eventMetadata is part of payload published in routing key and headers
+type eventMetadata struct {
+	TenantID string `json:"tenant_id"`
+	Version  *int64 `json:"version"`
 }
 
+// HandleEvents publishes event payload, version of user is duplicated in headers to order events without parsing payload
 func (t *outboxTransport) HandleEvents(ctx context.Context, correlationID, eventType, payload string) error {
 	l := t.logger.WithFields(logging.Fields{
 		"correlationID": correlationID,
 		"eventType":     eventType,
-		"payload":       payload,
 	})
+	if !hasSecrets(eventType) {
+		l = l.WithField("payload", payload)
+	}
 
-	err := t.producer.Publish(ctx, amqp.Delivery{
-		RoutingKey:    RoutingKeyPrefix + eventType,
-		CorrelationID: correlationID,
-		ContentType:   ContentType,
-		Type:          eventType,
-		Body:          []byte(payload),
-	})
+	var metadata eventMetadata
+	// payloads are produced by serializer, so metadata of payload that fails to parse is left empty
+	_ = json.Unmarshal([]byte(payload), &metadata)
+	delivery := Delivery{
+		Delivery: amqp.Delivery{
+			RoutingKey:    routingKey(eventType, metadata),
+			CorrelationID: correlationID,
+			ContentType:   ContentType,
+			Type:          eventType,
+			Body:          []byte(payload),
+		},
+	}
+	if metadata.Version != nil {
+		delivery.Headers = map[string]interface{}{VersionHeader: *metadata.Version}
+	}
+
+	err := t.producer.Publish(ctx, delivery)
 	if err != nil {
 		l.Error(err, "failed to publish event")
 		return err
@@ -50,3 +74,17 @@ func (t *outboxTransport) HandleEvents(ctx context.Context, correlationID, event
 	l.Info("successfully published event")
 	return nil
 }
+
+// routingKey ends with tenant for events of users and organizations, e.g. "user.user_created.default",
+// events of roles are shared by tenants and have no tenant in routing key
+func routingKey(eventType string, metadata eventMetadata) string {
+	if metadata.TenantID == "" {
+		return RoutingKeyPrefix + eventType
+	}
+	return RoutingKeyPrefix + eventType + "." + metadata.TenantID
+}
+
+// hasSecrets reports whether payload of event carries secrets, such payloads are never logged
+func hasSecrets(eventType string) bool {
+	return eventType == model.ContactVerificationRequested{}.Type()
+}
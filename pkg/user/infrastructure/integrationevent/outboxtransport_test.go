@@ -0,0 +1,87 @@
+package integrationevent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	rabbitmq "github.com/rabbitmq/amqp091-go"
+
+	"userservice/pkg/user/domain/model"
+)
+
+func TestOutboxTransportRoutingKey(t *testing.T) {
+	currentTime := time.Unix(1700000000, 0)
+	tests := []struct {
+		name       string
+		event      interface{ Type() string }
+		routingKey string
+		version    *int64
+	}{
+		{
+			name:       "user event",
+			event:      &model.UserDeleted{UserID: uuid.New(), TenantID: "default", DeletedAt: currentTime, Version: 3},
+			routingKey: "user.user_deleted.default",
+			version:    ptr(int64(3)),
+		},
+		{
+			name:       "user event of other tenant",
+			event:      &model.UserRoleAssigned{UserID: uuid.New(), TenantID: "acme", Role: "admin", AssignedAt: currentTime, Version: 7},
+			routingKey: "user.user_role_assigned.acme",
+			version:    ptr(int64(7)),
+		},
+		{
+			name:       "organization event",
+			event:      &model.OrganizationMemberRemoved{OrganizationID: uuid.New(), TenantID: "acme", UserID: uuid.New(), RemovedAt: currentTime},
+			routingKey: "user.organization_member_removed.acme",
+		},
+		{
+			name:       "role event shared by tenants",
+			event:      &model.RoleUpdated{Role: "admin", Permissions: []string{"users:read"}, UpdatedAt: currentTime},
+			routingKey: "user.role_updated",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := NewEventSerializer().Serialize(tt.event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			producer := &recordingProducer{}
+			err = NewOutboxTransport(nopLogger{}, producer).HandleEvents(context.Background(), "correlation", tt.event.Type(), payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			delivery := producer.deliveries[0]
+			if delivery.RoutingKey != tt.routingKey {
+				t.Errorf("routing key = %q, want %q", delivery.RoutingKey, tt.routingKey)
+			}
+			version, ok := delivery.Headers[VersionHeader]
+			switch {
+			case tt.version == nil && ok:
+				t.Errorf("version header = %v, want none", version)
+			case tt.version != nil && version != *tt.version:
+				t.Errorf("version header = %v, want %d", version, *tt.version)
+			}
+		})
+	}
+}
+
+type recordingProducer struct {
+	deliveries []Delivery
+}
+
+func (p *recordingProducer) Connect(*rabbitmq.Connection) error {
+	return nil
+}
+
+func (p *recordingProducer) Publish(_ context.Context, delivery Delivery) error {
+	p.deliveries = append(p.deliveries, delivery)
+	return nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
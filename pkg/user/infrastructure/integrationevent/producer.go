@@ -0,0 +1,237 @@
+package integrationevent
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/amqp"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	rabbitmq "github.com/rabbitmq/amqp091-go"
+)
+
+var (
+	errChannelClosed      = errors.New("amqp channel is closed")
+	errDeliveryNotAcked   = errors.New("delivery is not acknowledged by broker")
+	errDeliveryUnroutable = errors.New("delivery is returned by broker as unroutable")
+)
+
+// Delivery is amqp.Delivery with headers, producer of golib publishes deliveries without headers
+type Delivery struct {
+	amqp.Delivery
+	Headers map[string]interface{}
+}
+
+type Producer interface {
+	amqp.Channel
+	Publish(ctx context.Context, delivery Delivery) error
+}
+
+// NewProducer returns producer publishing to exchange it declares, queues and their bindings are declared by consumers.
+// Producer is added to amqp.Connection before consumers, so exchange exists when queues are bound to it
+func NewProducer(appID string, exchangeConfig amqp.ExchangeConfig, logger logging.Logger) Producer {
+	return &producer{
+		appID:          appID,
+		exchangeConfig: exchangeConfig,
+		logger:         logger,
+		reconnectDelay: time.Second,
+	}
+}
+
+// producerChannel is part of amqp channel used by producer, it is replaced in tests
+type producerChannel interface {
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args rabbitmq.Table) error
+	Confirm(noWait bool) error
+	NotifyClose(c chan *rabbitmq.Error) chan *rabbitmq.Error
+	NotifyReturn(c chan rabbitmq.Return) chan rabbitmq.Return
+	Publish(ctx context.Context, exchange, key string, mandatory, immediate bool, msg rabbitmq.Publishing) (confirmation, error)
+	IsClosed() bool
+	Close() error
+}
+
+type confirmation interface {
+	WaitContext(ctx context.Context) (bool, error)
+}
+
+// producerConnection opens channels of producer, it is replaced in tests
+type producerConnection interface {
+	Channel() (producerChannel, error)
+	IsClosed() bool
+}
+
+const returnsBufferSize = 16
+
+type producer struct {
+	appID          string
+	exchangeConfig amqp.ExchangeConfig
+	logger         logging.Logger
+	reconnectDelay time.Duration
+
+	mu      sync.Mutex
+	conn    producerConnection
+	channel producerChannel
+	returns chan rabbitmq.Return
+	// publishMu serializes publishing, so message returned by broker is matched with delivery being published
+	publishMu sync.Mutex
+}
+
+func (p *producer) Connect(conn *rabbitmq.Connection) error {
+	return p.connect(rabbitConnection{conn: conn})
+}
+
+func (p *producer) Publish(ctx context.Context, delivery Delivery) error {
+	p.publishMu.Lock()
+	defer p.publishMu.Unlock()
+
+	p.mu.Lock()
+	channel, returns := p.channel, p.returns
+	p.mu.Unlock()
+	if channel == nil || channel.IsClosed() {
+		return errors.WithStack(errChannelClosed)
+	}
+
+	// message id identifies returned message, returns of deliveries abandoned by cancelled context are skipped
+	drainReturns(returns, "")
+	messageID := uuid.NewString()
+	c, err := channel.Publish(
+		ctx,
+		p.exchangeConfig.Name,
+		delivery.RoutingKey,
+		true,
+		false,
+		rabbitmq.Publishing{
+			Headers:       delivery.Headers,
+			ContentType:   delivery.ContentType,
+			DeliveryMode:  rabbitmq.Persistent,
+			CorrelationId: delivery.CorrelationID,
+			MessageId:     messageID,
+			Timestamp:     time.Now(),
+			Type:          delivery.Type,
+			AppId:         p.appID,
+			Body:          delivery.Body,
+		},
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	ok, err := c.WaitContext(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !ok {
+		return errors.WithStack(errDeliveryNotAcked)
+	}
+
+	// broker sends return of unroutable message before its ack, so return is already received when ack is
+	if drainReturns(returns, messageID) {
+		return errors.Wrapf(errDeliveryUnroutable, "routing key %q", delivery.RoutingKey)
+	}
+	return nil
+}
+
+// drainReturns empties buffer of returned messages and reports whether message with messageID was returned
+func drainReturns(returns <-chan rabbitmq.Return, messageID string) bool {
+	returned := false
+	for {
+		select {
+		case r := <-returns:
+			returned = returned || (messageID != "" && r.MessageId == messageID)
+		default:
+			return returned
+		}
+	}
+}
+
+func (p *producer) connect(conn producerConnection) (err error) {
+	channel, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			closeErr := channel.Close()
+			if closeErr != nil {
+				p.logger.Error(closeErr, "failed to close AMQP channel")
+			}
+		}
+	}()
+
+	err = channel.ExchangeDeclare(
+		p.exchangeConfig.Name,
+		p.exchangeConfig.Kind,
+		p.exchangeConfig.Durable,
+		p.exchangeConfig.AutoDelete,
+		p.exchangeConfig.Internal,
+		p.exchangeConfig.NoWait,
+		p.exchangeConfig.Args,
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = channel.Confirm(false)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// returns are buffered as channel delivers them synchronously and publishing reads them only after ack
+	returns := channel.NotifyReturn(make(chan rabbitmq.Return, returnsBufferSize))
+
+	go p.reconnectOnClose(channel.NotifyClose(make(chan *rabbitmq.Error, 1)))
+
+	p.mu.Lock()
+	p.conn = conn
+	p.channel = channel
+	p.returns = returns
+	p.mu.Unlock()
+	return nil
+}
+
+// reconnectOnClose opens new channel when channel is closed by error, closed connection is restored by amqp.Connection
+func (p *producer) reconnectOnClose(ch chan *rabbitmq.Error) {
+	err := <-ch
+	if err == nil {
+		return
+	}
+
+	p.logger.Error(err, "AMQP channel error, trying to reconnect")
+	for {
+		p.mu.Lock()
+		conn := p.conn
+		p.mu.Unlock()
+		if conn.IsClosed() {
+			return
+		}
+		err := p.connect(conn)
+		if err == nil {
+			p.logger.Info("AMQP channel restored")
+			return
+		}
+		p.logger.Error(err, "failed to reconnect to AMQP channel")
+		time.Sleep(p.reconnectDelay)
+	}
+}
+
+type rabbitConnection struct {
+	conn *rabbitmq.Connection
+}
+
+func (c rabbitConnection) Channel() (producerChannel, error) {
+	channel, err := c.conn.Channel()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return rabbitChannel{Channel: channel}, nil
+}
+
+func (c rabbitConnection) IsClosed() bool {
+	return c.conn.IsClosed()
+}
+
+type rabbitChannel struct {
+	*rabbitmq.Channel
+}
+
+func (c rabbitChannel) Publish(ctx context.Context, exchange, key string, mandatory, immediate bool, msg rabbitmq.Publishing) (confirmation, error) {
+	return c.PublishWithDeferredConfirmWithContext(ctx, exchange, key, mandatory, immediate, msg)
+}
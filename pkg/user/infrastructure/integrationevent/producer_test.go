@@ -0,0 +1,240 @@
+package integrationevent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/amqp"
+	rabbitmq "github.com/rabbitmq/amqp091-go"
+)
+
+func TestProducerPublish(t *testing.T) {
+	tests := []struct {
+		name        string
+		ack         bool
+		returned    bool
+		staleReturn bool
+		closed      bool
+		err         error
+	}{
+		{name: "acked", ack: true},
+		{name: "nacked", err: errDeliveryNotAcked},
+		{name: "returned as unroutable", ack: true, returned: true, err: errDeliveryUnroutable},
+		{name: "return of other delivery", ack: true, staleReturn: true},
+		{name: "closed channel", closed: true, err: errChannelClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &fakeConnection{}
+			p := newTestProducer()
+			err := p.connect(conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			channel := conn.channel(0)
+			channel.ack = tt.ack
+			channel.returnUnroutable = tt.returned
+			channel.closed = tt.closed
+			if tt.staleReturn {
+				channel.returns <- rabbitmq.Return{MessageId: "stale"}
+			}
+
+			err = p.Publish(context.Background(), Delivery{
+				Delivery: amqp.Delivery{Type: "user_updated", RoutingKey: "user.user_updated"},
+				Headers:  map[string]interface{}{VersionHeader: int64(2)},
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Publish() error = %v, want %v", err, tt.err)
+			}
+			if tt.closed {
+				return
+			}
+			published := channel.published[0]
+			if published.Headers[VersionHeader] != int64(2) || published.MessageId == "" || published.DeliveryMode != rabbitmq.Persistent {
+				t.Errorf("Publish() published %+v", published)
+			}
+		})
+	}
+}
+
+func TestProducerReconnect(t *testing.T) {
+	conn := &fakeConnection{}
+	p := newTestProducer()
+	err := p.connect(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn.failNextChannel()
+	closedChannel := conn.channel(0)
+	closedChannel.closeWithError()
+	// the first attempt fails, the second one opens new channel
+	deadline := time.Now().Add(time.Second)
+	for p.currentChannel() == closedChannel && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if conn.opened() != 2 {
+		t.Fatalf("opened %d channels after close, want 2", conn.opened())
+	}
+
+	channel := conn.channel(1)
+	channel.ack = true
+	err = p.Publish(context.Background(), Delivery{Delivery: amqp.Delivery{Type: "user_updated"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(channel.published) != 1 {
+		t.Errorf("published %d deliveries to new channel, want 1", len(channel.published))
+	}
+}
+
+func TestProducerDoesNotReconnectClosedConnection(t *testing.T) {
+	conn := &fakeConnection{}
+	p := newTestProducer()
+	err := p.connect(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn.close()
+	conn.channel(0).closeWithError()
+	time.Sleep(10 * time.Millisecond)
+	if conn.opened() != 1 {
+		t.Errorf("opened %d channels after connection is closed, want 1", conn.opened())
+	}
+}
+
+func (p *producer) currentChannel() producerChannel {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.channel
+}
+
+func newTestProducer() *producer {
+	return &producer{
+		exchangeConfig: amqp.ExchangeConfig{Name: "user_events", Kind: "topic"},
+		logger:         nopLogger{},
+		reconnectDelay: time.Millisecond,
+	}
+}
+
+type fakeConnection struct {
+	mu       sync.Mutex
+	channels []*fakeChannel
+	// failures is number of channels that fail to open before channel opens
+	failures int
+	closed   bool
+}
+
+func (c *fakeConnection) Channel() (producerChannel, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures > 0 {
+		c.failures--
+		return nil, errors.New("channel is not opened")
+	}
+	channel := &fakeChannel{}
+	c.channels = append(c.channels, channel)
+	return channel, nil
+}
+
+func (c *fakeConnection) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+func (c *fakeConnection) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+}
+
+func (c *fakeConnection) failNextChannel() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures++
+}
+
+func (c *fakeConnection) opened() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.channels)
+}
+
+func (c *fakeConnection) channel(i int) *fakeChannel {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.channels[i]
+}
+
+type fakeChannel struct {
+	ack              bool
+	returnUnroutable bool
+	closed           bool
+	published        []rabbitmq.Publishing
+	closes           chan *rabbitmq.Error
+	returns          chan rabbitmq.Return
+}
+
+func (c *fakeChannel) ExchangeDeclare(string, string, bool, bool, bool, bool, rabbitmq.Table) error {
+	return nil
+}
+
+func (c *fakeChannel) Confirm(bool) error {
+	return nil
+}
+
+func (c *fakeChannel) NotifyClose(ch chan *rabbitmq.Error) chan *rabbitmq.Error {
+	c.closes = ch
+	return ch
+}
+
+func (c *fakeChannel) NotifyReturn(ch chan rabbitmq.Return) chan rabbitmq.Return {
+	c.returns = ch
+	return ch
+}
+
+// Publish returns unroutable message before confirmation as broker does
+func (c *fakeChannel) Publish(_ context.Context, _, _ string, _, _ bool, msg rabbitmq.Publishing) (confirmation, error) {
+	c.published = append(c.published, msg)
+	if c.returnUnroutable {
+		c.returns <- rabbitmq.Return{MessageId: msg.MessageId}
+	}
+	return fakeConfirmation{ack: c.ack}, nil
+}
+
+func (c *fakeChannel) IsClosed() bool {
+	return c.closed
+}
+
+func (c *fakeChannel) Close() error {
+	return nil
+}
+
+func (c *fakeChannel) closeWithError() {
+	c.closed = true
+	c.closes <- &rabbitmq.Error{Code: rabbitmq.ChannelError, Reason: "channel error"}
+	close(c.closes)
+}
+
+type fakeConfirmation struct {
+	ack bool
+}
+
+func (c fakeConfirmation) WaitContext(context.Context) (bool, error) {
+	return c.ack, nil
+}
+
+type nopLogger struct{}
+
+func (l nopLogger) WithField(string, interface{}) logging.Logger { return l }
+func (l nopLogger) WithFields(logging.Fields) logging.Logger     { return l }
+func (l nopLogger) Info(...interface{})                          {}
+func (l nopLogger) Error(error, ...interface{})                  {}
+func (l nopLogger) Warning(error, ...interface{})                {}
+func (l nopLogger) Debug(...interface{})                         {}
@@ -2,8 +2,10 @@ package integrationevent
 
 import (
 	"encoding/json"
+	"time"
 
 	"gitea.xscloud.ru/xscloud/golib/pkg/application/outbox"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
 	"userservice/pkg/user/domain/model"
@@ -19,47 +21,208 @@ func (s eventSerializer) Serialize(event outbox.Event) (string, error) {
 	switch e := event.(type) {
 	case *model.UserCreated:
 		b, err := json.Marshal(UserCreated{
-			UserID:    e.UserID.String(),
-			Status:    int(e.Status),
-			Login:     e.Login,
-			Email:     e.Email,
-			Telegram:  e.Telegram,
-			CreatedAt: e.CreatedAt.Unix(),
+			UserID:      e.UserID.String(),
+			TenantID:    e.TenantID,
+			Status:      int(e.Status),
+			Login:       e.Login,
+			DisplayName: e.Profile.DisplayName,
+			Locale:      e.Profile.Locale,
+			Timezone:    e.Profile.Timezone,
+			AvatarURL:   e.Profile.AvatarURL,
+			Contacts:    toContacts(e.Contacts),
+			Email:       primaryContactValue(e.Contacts, model.ContactEmail),
+			Telegram:    primaryContactValue(e.Contacts, model.ContactTelegram),
+			Version:     e.Version,
+			CreatedAt:   e.CreatedAt.Unix(),
 		})
 		return string(b), errors.WithStack(err)
 	case *model.UserUpdated:
 		ie := UserUpdated{
-			UserID:    e.UserID.String(),
-			UpdatedAt: e.UpdatedAt.Unix(),
-		}
-		if e.UpdatedFields != nil {
-			ie.UpdatedFields = &struct {
-				Status   *int    `json:"status,omitempty"`
-				Email    *string `json:"email,omitempty"`
-				Telegram *string `json:"telegram,omitempty"`
-			}{
-				Status:   (*int)(e.UpdatedFields.Status),
-				Email:    e.UpdatedFields.Email,
-				Telegram: e.UpdatedFields.Telegram,
-			}
+			UserID:         e.UserID.String(),
+			TenantID:       e.TenantID,
+			UpdatedFields:  toUpdatedFields(e.UpdatedFields),
+			PreviousFields: toUpdatedFields(e.PreviousFields),
+			Version:        e.Version,
+			UpdatedAt:      e.UpdatedAt.Unix(),
 		}
 		if e.RemovedFields != nil {
-			ie.RemovedFields = &struct {
-				Email    *bool `json:"email,omitempty"`
-				Telegram *bool `json:"telegram,omitempty"`
-			}{
-				Email:    e.RemovedFields.Email,
-				Telegram: e.RemovedFields.Telegram,
+			ie.RemovedFields = &RemovedFields{
+				StatusExpiresAt:         e.RemovedFields.StatusExpiresAt,
+				Contacts:                toContacts(e.RemovedFields.Contacts),
+				Email:                   primaryContactRemoved(e.UpdatedFields, e.RemovedFields, model.ContactEmail),
+				Telegram:                primaryContactRemoved(e.UpdatedFields, e.RemovedFields, model.ContactTelegram),
+				DisplayName:             e.RemovedFields.DisplayName,
+				Locale:                  e.RemovedFields.Locale,
+				Timezone:                e.RemovedFields.Timezone,
+				AvatarURL:               e.RemovedFields.AvatarURL,
+				Attributes:              e.RemovedFields.Attributes,
+				Labels:                  e.RemovedFields.Labels,
+				NotificationPreferences: toNotificationPreferences(e.RemovedFields.NotificationPreferences),
 			}
 		}
 		b, err := json.Marshal(ie)
 		return string(b), errors.WithStack(err)
+	case *model.ContactVerificationRequested:
+		b, err := json.Marshal(ContactVerificationRequested{
+			UserID:    e.UserID.String(),
+			TenantID:  e.TenantID,
+			Contact:   toContacts([]model.Contact{e.Contact})[0],
+			Code:      e.Code,
+			ExpiresAt: e.ExpiresAt.Unix(),
+			Version:   e.Version,
+		})
+		return string(b), errors.WithStack(err)
 	case *model.UserDeleted:
 		b, err := json.Marshal(UserDeleted{
 			UserID:    e.UserID.String(),
+			TenantID:  e.TenantID,
 			Status:    int(e.Status),
 			DeletedAt: e.DeletedAt.Unix(),
 			Hard:      e.Hard,
+			Version:   e.Version,
+		})
+		return string(b), errors.WithStack(err)
+	case *model.UserRestored:
+		b, err := json.Marshal(UserRestored{
+			UserID:     e.UserID.String(),
+			TenantID:   e.TenantID,
+			Status:     int(e.Status),
+			RestoredAt: e.RestoredAt.Unix(),
+			Version:    e.Version,
+		})
+		return string(b), errors.WithStack(err)
+	case *model.UserMerged:
+		b, err := json.Marshal(UserMerged{
+			SourceUserID:            e.SourceUserID.String(),
+			TargetUserID:            e.TargetUserID.String(),
+			TenantID:                e.TenantID,
+			MergedAt:                e.MergedAt.Unix(),
+			SourceVersion:           e.SourceVersion,
+			TargetVersion:           e.TargetVersion,
+			Labels:                  e.Labels,
+			NotificationPreferences: toNotificationPreferences(e.NotificationPreferences),
+			Roles:                   e.Roles,
+			OrganizationIDs:         toStrings(e.OrganizationIDs),
+		})
+		return string(b), errors.WithStack(err)
+	case *model.PasswordChanged:
+		b, err := json.Marshal(PasswordChanged{
+			UserID:    e.UserID.String(),
+			TenantID:  e.TenantID,
+			ChangedAt: e.ChangedAt.Unix(),
+			Version:   e.Version,
+		})
+		return string(b), errors.WithStack(err)
+	case *model.APITokenCreated:
+		b, err := json.Marshal(APITokenCreated{
+			UserID:    e.UserID.String(),
+			TenantID:  e.TenantID,
+			TokenID:   e.TokenID.String(),
+			Name:      e.Name,
+			Scopes:    e.Scopes,
+			ExpiresAt: toUnix(e.ExpiresAt),
+			CreatedAt: e.CreatedAt.Unix(),
+			Version:   e.Version,
+		})
+		return string(b), errors.WithStack(err)
+	case *model.APITokenRevoked:
+		b, err := json.Marshal(APITokenRevoked{
+			UserID:    e.UserID.String(),
+			TenantID:  e.TenantID,
+			TokenID:   e.TokenID.String(),
+			RevokedAt: e.RevokedAt.Unix(),
+			Version:   e.Version,
+		})
+		return string(b), errors.WithStack(err)
+	case *model.TOTPEnrolled:
+		b, err := json.Marshal(TOTPEnrolled{
+			UserID:     e.UserID.String(),
+			TenantID:   e.TenantID,
+			EnrolledAt: e.EnrolledAt.Unix(),
+			Version:    e.Version,
+		})
+		return string(b), errors.WithStack(err)
+	case *model.TOTPRemoved:
+		b, err := json.Marshal(TOTPRemoved{
+			UserID:    e.UserID.String(),
+			TenantID:  e.TenantID,
+			RemovedAt: e.RemovedAt.Unix(),
+			Version:   e.Version,
+		})
+		return string(b), errors.WithStack(err)
+	case *model.IdentityLinked:
+		b, err := json.Marshal(IdentityLinked{
+			UserID:   e.UserID.String(),
+			TenantID: e.TenantID,
+			Issuer:   e.Issuer,
+			Subject:  e.Subject,
+			LinkedAt: e.LinkedAt.Unix(),
+			Version:  e.Version,
+		})
+		return string(b), errors.WithStack(err)
+	case *model.IdentityUnlinked:
+		b, err := json.Marshal(IdentityUnlinked{
+			UserID:     e.UserID.String(),
+			TenantID:   e.TenantID,
+			Issuer:     e.Issuer,
+			Subject:    e.Subject,
+			UnlinkedAt: e.UnlinkedAt.Unix(),
+			Version:    e.Version,
+		})
+		return string(b), errors.WithStack(err)
+	case *model.RoleUpdated:
+		b, err := json.Marshal(RoleUpdated{
+			Role:        e.Role,
+			Permissions: e.Permissions,
+			UpdatedAt:   e.UpdatedAt.Unix(),
+		})
+		return string(b), errors.WithStack(err)
+	case *model.UserRoleAssigned:
+		b, err := json.Marshal(UserRoleAssigned{
+			UserID:     e.UserID.String(),
+			TenantID:   e.TenantID,
+			Role:       e.Role,
+			AssignedAt: e.AssignedAt.Unix(),
+			Version:    e.Version,
+		})
+		return string(b), errors.WithStack(err)
+	case *model.UserRoleRevoked:
+		b, err := json.Marshal(UserRoleRevoked{
+			UserID:    e.UserID.String(),
+			TenantID:  e.TenantID,
+			Role:      e.Role,
+			RevokedAt: e.RevokedAt.Unix(),
+			Version:   e.Version,
+		})
+		return string(b), errors.WithStack(err)
+	case *model.OrganizationCreated:
+		b, err := json.Marshal(OrganizationCreated{
+			OrganizationID: e.OrganizationID.String(),
+			TenantID:       e.TenantID,
+			Name:           e.Name,
+			OwnerID:        e.OwnerID.String(),
+			CreatedAt:      e.CreatedAt.Unix(),
+		})
+		return string(b), errors.WithStack(err)
+	case *model.OrganizationMemberStored:
+		b, err := json.Marshal(OrganizationMemberStored{
+			OrganizationID: e.OrganizationID.String(),
+			TenantID:       e.TenantID,
+			Member: Member{
+				UserID:      e.Member.UserID.String(),
+				Role:        membershipRoles[e.Member.Role],
+				DisplayName: e.Member.DisplayName,
+				JoinedAt:    e.Member.JoinedAt.Unix(),
+			},
+			UpdatedAt: e.UpdatedAt.Unix(),
+		})
+		return string(b), errors.WithStack(err)
+	case *model.OrganizationMemberRemoved:
+		b, err := json.Marshal(OrganizationMemberRemoved{
+			OrganizationID: e.OrganizationID.String(),
+			TenantID:       e.TenantID,
+			UserID:         e.UserID.String(),
+			RemovedAt:      e.RemovedAt.Unix(),
 		})
 		return string(b), errors.WithStack(err)
 	default:
@@ -68,31 +231,475 @@ func (s eventSerializer) Serialize(event outbox.Event) (string, error) {
 }
 
 type UserCreated struct {
-	UserID    string  `json:"user_id"`
-	Status    int     `json:"status"`
-	Login     string  `json:"login"`
-	Email     *string `json:"email,omitempty"`
-	Telegram  *string `json:"telegram,omitempty"`
-	CreatedAt int64   `json:"created_at"`
+	UserID   string `json:"user_id"`
+	TenantID string `json:"tenant_id"`
+	// Status is one of 0 - blocked, 1 - active, 2 - deleted, 3 - pending, 4 - suspended
+	Status      int       `json:"status"`
+	Login       string    `json:"login"`
+	DisplayName *string   `json:"display_name,omitempty"`
+	Locale      *string   `json:"locale,omitempty"`
+	Timezone    *string   `json:"timezone,omitempty"`
+	AvatarURL   *string   `json:"avatar_url,omitempty"`
+	Contacts    []Contact `json:"contacts,omitempty"`
+	// Deprecated: use primary email of Contacts
+	Email *string `json:"email,omitempty"`
+	// Deprecated: use primary telegram of Contacts
+	Telegram *string `json:"telegram,omitempty"`
+	// Version is sequence number of user change, consumers should skip events with version not greater than processed one
+	Version   int64 `json:"version"`
+	CreatedAt int64 `json:"created_at"`
 }
 
 type UserUpdated struct {
-	UserID        string `json:"user_id"`
-	UpdatedFields *struct {
-		Status   *int    `json:"status,omitempty"`
-		Email    *string `json:"email,omitempty"`
-		Telegram *string `json:"telegram,omitempty"`
-	} `json:"updated_fields,omitempty"`
-	RemovedFields *struct {
-		Email    *bool `json:"email,omitempty"`
-		Telegram *bool `json:"telegram,omitempty"`
-	} `json:"removed_fields,omitempty"`
-	UpdatedAt int64 `json:"updated_at,omitempty"`
+	UserID        string         `json:"user_id"`
+	TenantID      string         `json:"tenant_id"`
+	UpdatedFields *UpdatedFields `json:"updated_fields,omitempty"`
+	RemovedFields *RemovedFields `json:"removed_fields,omitempty"`
+	// PreviousFields contains values updated and removed fields had before the change
+	PreviousFields *UpdatedFields `json:"previous_fields,omitempty"`
+	Version        int64          `json:"version"`
+	UpdatedAt      int64          `json:"updated_at,omitempty"`
+}
+
+type UpdatedFields struct {
+	Status          *int      `json:"status,omitempty"`
+	StatusSource    *int      `json:"status_source,omitempty"`
+	StatusExpiresAt *int64    `json:"status_expires_at,omitempty"`
+	Contacts        []Contact `json:"contacts,omitempty"`
+	// Deprecated: use primary email of Contacts
+	Email *string `json:"email,omitempty"`
+	// Deprecated: use primary telegram of Contacts
+	Telegram                *string                  `json:"telegram,omitempty"`
+	DisplayName             *string                  `json:"display_name,omitempty"`
+	Locale                  *string                  `json:"locale,omitempty"`
+	Timezone                *string                  `json:"timezone,omitempty"`
+	AvatarURL               *string                  `json:"avatar_url,omitempty"`
+	Attributes              []Attribute              `json:"attributes,omitempty"`
+	Labels                  []string                 `json:"labels,omitempty"`
+	NotificationPreferences []NotificationPreference `json:"notification_preferences,omitempty"`
+}
+
+type RemovedFields struct {
+	StatusExpiresAt bool      `json:"status_expires_at,omitempty"`
+	Contacts        []Contact `json:"contacts,omitempty"`
+	// Deprecated: set when user has no email contacts left, use Contacts
+	Email bool `json:"email,omitempty"`
+	// Deprecated: set when user has no telegram contacts left, use Contacts
+	Telegram    bool `json:"telegram,omitempty"`
+	DisplayName bool `json:"display_name,omitempty"`
+	Locale      bool `json:"locale,omitempty"`
+	Timezone    bool `json:"timezone,omitempty"`
+	AvatarURL   bool `json:"avatar_url,omitempty"`
+	// Attributes contains keys of removed attributes
+	Attributes              []string                 `json:"attributes,omitempty"`
+	Labels                  []string                 `json:"labels,omitempty"`
+	NotificationPreferences []NotificationPreference `json:"notification_preferences,omitempty"`
+}
+
+type Attribute struct {
+	Key   string `json:"key"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type NotificationPreference struct {
+	Channel  string `json:"channel"`
+	Category string `json:"category"`
+	Enabled  bool   `json:"enabled"`
+}
+
+type Contact struct {
+	Type       string `json:"type"`
+	Value      string `json:"value"`
+	Primary    bool   `json:"primary"`
+	VerifiedAt *int64 `json:"verified_at,omitempty"`
+}
+
+type ContactVerificationRequested struct {
+	UserID    string  `json:"user_id"`
+	TenantID  string  `json:"tenant_id"`
+	Contact   Contact `json:"contact"`
+	Code      string  `json:"code"`
+	ExpiresAt int64   `json:"expires_at"`
+	Version   int64   `json:"version"`
 }
 
 type UserDeleted struct {
 	UserID    string `json:"user_id"`
+	TenantID  string `json:"tenant_id"`
 	Status    int    `json:"status"`
 	DeletedAt int64  `json:"deleted_at"`
 	Hard      bool   `json:"hard"`
+	Version   int64  `json:"version"`
+}
+
+type UserRestored struct {
+	UserID     string `json:"user_id"`
+	TenantID   string `json:"tenant_id"`
+	Status     int    `json:"status"`
+	RestoredAt int64  `json:"restored_at"`
+	Version    int64  `json:"version"`
+}
+
+type UserMerged struct {
+	SourceUserID  string `json:"source_user_id"`
+	TargetUserID  string `json:"target_user_id"`
+	TenantID      string `json:"tenant_id"`
+	MergedAt      int64  `json:"merged_at"`
+	SourceVersion int64  `json:"source_version"`
+	TargetVersion int64  `json:"target_version"`
+	// Labels, NotificationPreferences, Roles and OrganizationIDs of memberships source had are moved to target
+	Labels                  []string                 `json:"labels,omitempty"`
+	NotificationPreferences []NotificationPreference `json:"notification_preferences,omitempty"`
+	Roles                   []string                 `json:"roles,omitempty"`
+	OrganizationIDs         []string                 `json:"organization_ids,omitempty"`
+}
+
+type PasswordChanged struct {
+	UserID    string `json:"user_id"`
+	TenantID  string `json:"tenant_id"`
+	ChangedAt int64  `json:"changed_at"`
+	Version   int64  `json:"version"`
+}
+
+type APITokenCreated struct {
+	UserID    string   `json:"user_id"`
+	TenantID  string   `json:"tenant_id"`
+	TokenID   string   `json:"token_id"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt *int64   `json:"expires_at,omitempty"`
+	CreatedAt int64    `json:"created_at"`
+	Version   int64    `json:"version"`
+}
+
+type APITokenRevoked struct {
+	UserID    string `json:"user_id"`
+	TenantID  string `json:"tenant_id"`
+	TokenID   string `json:"token_id"`
+	RevokedAt int64  `json:"revoked_at"`
+	Version   int64  `json:"version"`
+}
+
+type TOTPEnrolled struct {
+	UserID     string `json:"user_id"`
+	TenantID   string `json:"tenant_id"`
+	EnrolledAt int64  `json:"enrolled_at"`
+	Version    int64  `json:"version"`
+}
+
+type TOTPRemoved struct {
+	UserID    string `json:"user_id"`
+	TenantID  string `json:"tenant_id"`
+	RemovedAt int64  `json:"removed_at"`
+	Version   int64  `json:"version"`
+}
+
+type IdentityLinked struct {
+	UserID   string `json:"user_id"`
+	TenantID string `json:"tenant_id"`
+	Issuer   string `json:"issuer"`
+	Subject  string `json:"subject"`
+	LinkedAt int64  `json:"linked_at"`
+	Version  int64  `json:"version"`
+}
+
+type IdentityUnlinked struct {
+	UserID     string `json:"user_id"`
+	TenantID   string `json:"tenant_id"`
+	Issuer     string `json:"issuer"`
+	Subject    string `json:"subject"`
+	UnlinkedAt int64  `json:"unlinked_at"`
+	Version    int64  `json:"version"`
+}
+
+type RoleUpdated struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	UpdatedAt   int64    `json:"updated_at"`
+}
+
+type UserRoleAssigned struct {
+	UserID     string `json:"user_id"`
+	TenantID   string `json:"tenant_id"`
+	Role       string `json:"role"`
+	AssignedAt int64  `json:"assigned_at"`
+	Version    int64  `json:"version"`
+}
+
+type UserRoleRevoked struct {
+	UserID    string `json:"user_id"`
+	TenantID  string `json:"tenant_id"`
+	Role      string `json:"role"`
+	RevokedAt int64  `json:"revoked_at"`
+	Version   int64  `json:"version"`
+}
+
+type OrganizationCreated struct {
+	OrganizationID string `json:"organization_id"`
+	TenantID       string `json:"tenant_id"`
+	Name           string `json:"name"`
+	OwnerID        string `json:"owner_id"`
+	CreatedAt      int64  `json:"created_at"`
+}
+
+type OrganizationMemberStored struct {
+	OrganizationID string `json:"organization_id"`
+	TenantID       string `json:"tenant_id"`
+	Member         Member `json:"member"`
+	UpdatedAt      int64  `json:"updated_at"`
+}
+
+type OrganizationMemberRemoved struct {
+	OrganizationID string `json:"organization_id"`
+	TenantID       string `json:"tenant_id"`
+	UserID         string `json:"user_id"`
+	RemovedAt      int64  `json:"removed_at"`
+}
+
+type Member struct {
+	UserID      string  `json:"user_id"`
+	Role        string  `json:"role"`
+	DisplayName *string `json:"display_name,omitempty"`
+	JoinedAt    int64   `json:"joined_at"`
+}
+
+var membershipRoles = map[model.MembershipRole]string{
+	model.MembershipMember: "member",
+	model.MembershipOwner:  "owner",
+}
+
+var contactTypes = map[model.ContactType]string{
+	model.ContactEmail:    "email",
+	model.ContactTelegram: "telegram",
+	model.ContactPhone:    "phone",
+}
+
+func toUpdatedFields(fields *model.UpdatedFields) *UpdatedFields {
+	if fields == nil {
+		return nil
+	}
+	return &UpdatedFields{
+		Status:                  (*int)(fields.Status),
+		StatusSource:            (*int)(fields.StatusSource),
+		StatusExpiresAt:         toUnix(fields.StatusExpiresAt),
+		Contacts:                toContacts(fields.Contacts),
+		Email:                   primaryContactValue(fields.Contacts, model.ContactEmail),
+		Telegram:                primaryContactValue(fields.Contacts, model.ContactTelegram),
+		DisplayName:             fields.DisplayName,
+		Locale:                  fields.Locale,
+		Timezone:                fields.Timezone,
+		AvatarURL:               fields.AvatarURL,
+		Attributes:              toAttributes(fields.Attributes),
+		Labels:                  fields.Labels,
+		NotificationPreferences: toNotificationPreferences(fields.NotificationPreferences),
+	}
+}
+
+func fromUpdatedFields(fields *UpdatedFields) (*model.UpdatedFields, error) {
+	if fields == nil {
+		return nil, nil
+	}
+	contacts, err := fromContacts(fields.Contacts)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := fromAttributes(fields.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	notificationPreferences, err := fromNotificationPreferences(fields.NotificationPreferences)
+	if err != nil {
+		return nil, err
+	}
+	return &model.UpdatedFields{
+		Status:                  (*model.UserStatus)(fields.Status),
+		StatusSource:            (*model.StatusSource)(fields.StatusSource),
+		StatusExpiresAt:         fromUnix(fields.StatusExpiresAt),
+		Contacts:                contacts,
+		DisplayName:             fields.DisplayName,
+		Locale:                  fields.Locale,
+		Timezone:                fields.Timezone,
+		AvatarURL:               fields.AvatarURL,
+		Attributes:              attributes,
+		Labels:                  fields.Labels,
+		NotificationPreferences: notificationPreferences,
+	}, nil
+}
+
+func toContacts(contacts []model.Contact) []Contact {
+	if len(contacts) == 0 {
+		return nil
+	}
+	result := make([]Contact, 0, len(contacts))
+	for _, contact := range contacts {
+		c := Contact{
+			Type:    contactTypes[contact.Type],
+			Value:   contact.Value,
+			Primary: contact.Primary,
+		}
+		c.VerifiedAt = toUnix(contact.VerifiedAt)
+		result = append(result, c)
+	}
+	return result
+}
+
+// primaryContactValue fills deprecated fields of consumers which do not know contacts yet
+func primaryContactValue(contacts []model.Contact, contactType model.ContactType) *string {
+	for _, contact := range contacts {
+		if contact.Type == contactType && contact.Primary {
+			value := contact.Value
+			return &value
+		}
+	}
+	return nil
+}
+
+// primaryContactRemoved reports that primary contact of type was removed and no other contact became primary,
+// contacts of each type have primary one, so user has no contacts of type left
+func primaryContactRemoved(updated *model.UpdatedFields, removed *model.RemovedFields, contactType model.ContactType) bool {
+	if primaryContactValue(removed.Contacts, contactType) == nil {
+		return false
+	}
+	return updated == nil || primaryContactValue(updated.Contacts, contactType) == nil
+}
+
+func fromContacts(contacts []Contact) ([]model.Contact, error) {
+	if len(contacts) == 0 {
+		return nil, nil
+	}
+	result := make([]model.Contact, 0, len(contacts))
+	for _, contact := range contacts {
+		contactType, ok := findContactType(contact.Type)
+		if !ok {
+			return nil, errors.Errorf("unknown contact type %q", contact.Type)
+		}
+		c := model.Contact{
+			Type:    contactType,
+			Value:   contact.Value,
+			Primary: contact.Primary,
+		}
+		c.VerifiedAt = fromUnix(contact.VerifiedAt)
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+func findContactType(name string) (model.ContactType, bool) {
+	for contactType, n := range contactTypes {
+		if n == name {
+			return contactType, true
+		}
+	}
+	return 0, false
+}
+
+func toNotificationPreferences(preferences []model.NotificationPreference) []NotificationPreference {
+	if len(preferences) == 0 {
+		return nil
+	}
+	result := make([]NotificationPreference, 0, len(preferences))
+	for _, preference := range preferences {
+		result = append(result, NotificationPreference{
+			Channel:  contactTypes[preference.Channel],
+			Category: preference.Category,
+			Enabled:  preference.Enabled,
+		})
+	}
+	return result
+}
+
+func fromNotificationPreferences(preferences []NotificationPreference) ([]model.NotificationPreference, error) {
+	if len(preferences) == 0 {
+		return nil, nil
+	}
+	result := make([]model.NotificationPreference, 0, len(preferences))
+	for _, preference := range preferences {
+		channel, ok := findContactType(preference.Channel)
+		if !ok {
+			return nil, errors.Errorf("unknown notification channel %q", preference.Channel)
+		}
+		result = append(result, model.NotificationPreference{
+			Channel:  channel,
+			Category: preference.Category,
+			Enabled:  preference.Enabled,
+		})
+	}
+	return result, nil
+}
+
+var attributeTypes = map[model.AttributeType]string{
+	model.AttributeString: "string",
+	model.AttributeInt:    "int",
+	model.AttributeBool:   "bool",
+}
+
+func toAttributes(attributes []model.Attribute) []Attribute {
+	if len(attributes) == 0 {
+		return nil
+	}
+	result := make([]Attribute, 0, len(attributes))
+	for _, attribute := range attributes {
+		result = append(result, Attribute{
+			Key:   attribute.Key,
+			Type:  attributeTypes[attribute.Type],
+			Value: attribute.Value,
+		})
+	}
+	return result
+}
+
+func fromAttributes(attributes []Attribute) ([]model.Attribute, error) {
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+	result := make([]model.Attribute, 0, len(attributes))
+	for _, attribute := range attributes {
+		attributeType, ok := findAttributeType(attribute.Type)
+		if !ok {
+			return nil, errors.Errorf("unknown attribute type %q", attribute.Type)
+		}
+		result = append(result, model.Attribute{
+			Key:   attribute.Key,
+			Type:  attributeType,
+			Value: attribute.Value,
+		})
+	}
+	return result, nil
+}
+
+func findAttributeType(name string) (model.AttributeType, bool) {
+	for attributeType, n := range attributeTypes {
+		if n == name {
+			return attributeType, true
+		}
+	}
+	return 0, false
+}
+
+func toUnix(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+	unix := t.Unix()
+	return &unix
+}
+
+func fromUnix(unix *int64) *time.Time {
+	if unix == nil {
+		return nil
+	}
+	t := time.Unix(*unix, 0)
+	return &t
+}
+
+func toStrings(ids []uuid.UUID) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, id.String())
+	}
+	return result
 }
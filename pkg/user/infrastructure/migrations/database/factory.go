@@ -7,6 +7,8 @@ import (
 	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
 	libmigrator "gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
 	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+
+	"userservice/pkg/user/domain/model"
 )
 
 type MigrationBuilderFunc func(client mysql.ClientContext) libmigrator.Migration
@@ -16,6 +18,7 @@ func NewDatabaseMigrator(
 	ctx context.Context,
 	pool mysql.ConnectionPool,
 	logger logging.Logger,
+	emailNormalization model.EmailNormalization,
 ) (migrator libmigrator.Migrator, release ReleaseConnectionFunc, err error) {
 	conn, err2 := pool.TransactionalConnection(ctx)
 	if err2 != nil {
@@ -30,8 +33,9 @@ func NewDatabaseMigrator(
 	l := logger.WithField("migrator", "database")
 	factory := libmigrator.NewMigratorFactory("database", conn, l)
 
-	migrations := make([]libmigrator.Migration, 0, len(builderFunctions))
-	for _, builder := range builderFunctions {
+	builders := builderFunctions(emailNormalization)
+	migrations := make([]libmigrator.Migration, 0, len(builders))
+	for _, builder := range builders {
 		migrations = append(migrations, builder(conn))
 	}
 
@@ -42,6 +46,31 @@ func NewDatabaseMigrator(
 	return migrator, conn.Close, nil
 }
 
-var builderFunctions = []MigrationBuilderFunc{
-	NewVersion1722266003,
+// builderFunctions lists migrations in order of versions, some backfills depend on configuration of service
+func builderFunctions(emailNormalization model.EmailNormalization) []MigrationBuilderFunc {
+	return []MigrationBuilderFunc{
+		NewVersion1722266003,
+		NewVersion1792404361,
+		NewVersion1792404490,
+		NewVersion1792404625,
+		NewVersion1792404723,
+		NewVersion1792405075,
+		NewVersion1792405214,
+		NewVersion1792405363,
+		NewVersion1792405444,
+		NewVersion1792405685,
+		NewVersion1792405855,
+		NewVersion1792405982,
+		func(client mysql.ClientContext) libmigrator.Migration {
+			return NewVersion1792406100(client, emailNormalization)
+		},
+		NewVersion1792406175,
+		NewVersion1792406537,
+		NewVersion1792406678,
+		NewVersion1792407006,
+		NewVersion1792407227,
+		NewVersion1792407365,
+		NewVersion1792407492,
+		NewVersion1792407611,
+	}
 }
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792404361(client mysql.ClientContext) migrator.Migration {
+	return &version1792404361{
+		client: client,
+	}
+}
+
+type version1792404361 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792404361) Version() int64 {
+	return 1792404361
+}
+
+func (v version1792404361) Description() string {
+	return "Move user email and telegram to 'user_contact' table"
+}
+
+func (v version1792404361) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		CREATE TABLE user_contact
+		(
+		    user_id    VARCHAR(64)  NOT NULL,
+		    type       INT          NOT NULL,
+		    value      VARCHAR(255) NOT NULL,
+		    is_primary TINYINT(1)   NOT NULL,
+		    PRIMARY KEY (user_id, type, value),
+		    UNIQUE INDEX type_value_idx (type, value)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// 0 - email, 1 - telegram contact types
+	_, err = v.client.ExecContext(ctx, `
+		INSERT INTO user_contact (user_id, type, value, is_primary)
+		SELECT user_id, 0, email, 1 FROM user WHERE email IS NOT NULL
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = v.client.ExecContext(ctx, `
+		INSERT INTO user_contact (user_id, type, value, is_primary)
+		SELECT user_id, 1, telegram, 1 FROM user WHERE telegram IS NOT NULL
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = v.client.ExecContext(ctx, `ALTER TABLE user DROP COLUMN email, DROP COLUMN telegram`)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792404490(client mysql.ClientContext) migrator.Migration {
+	return &version1792404490{
+		client: client,
+	}
+}
+
+type version1792404490 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792404490) Version() int64 {
+	return 1792404490
+}
+
+func (v version1792404490) Description() string {
+	return "Add contact verification"
+}
+
+func (v version1792404490) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `ALTER TABLE user_contact ADD COLUMN verified_at DATETIME`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// contacts stored before verification was introduced are treated as verified
+	_, err = v.client.ExecContext(ctx, `UPDATE user_contact SET verified_at = NOW()`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = v.client.ExecContext(ctx, `
+		CREATE TABLE user_contact_verification
+		(
+		    user_id         VARCHAR(64)  NOT NULL,
+		    type            INT          NOT NULL,
+		    value           VARCHAR(255) NOT NULL,
+		    code_hash       VARCHAR(64)  NOT NULL,
+		    expires_at      DATETIME     NOT NULL,
+		    failed_attempts INT          NOT NULL DEFAULT 0,
+		    PRIMARY KEY (user_id, type, value)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	return errors.WithStack(err)
+}
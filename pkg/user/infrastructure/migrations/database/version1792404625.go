@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792404625(client mysql.ClientContext) migrator.Migration {
+	return &version1792404625{
+		client: client,
+	}
+}
+
+type version1792404625 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792404625) Version() int64 {
+	return 1792404625
+}
+
+func (v version1792404625) Description() string {
+	return "Add user profile fields"
+}
+
+func (v version1792404625) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		ALTER TABLE user
+		    ADD COLUMN display_name VARCHAR(255),
+		    ADD COLUMN locale       VARCHAR(64),
+		    ADD COLUMN timezone     VARCHAR(64),
+		    ADD COLUMN avatar_url   VARCHAR(2048)
+	`)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792404723(client mysql.ClientContext) migrator.Migration {
+	return &version1792404723{
+		client: client,
+	}
+}
+
+type version1792404723 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792404723) Version() int64 {
+	return 1792404723
+}
+
+func (v version1792404723) Description() string {
+	return "Create 'user_attribute' and 'user_label' tables"
+}
+
+func (v version1792404723) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		CREATE TABLE user_attribute
+		(
+		    user_id VARCHAR(64)  NOT NULL,
+		    name    VARCHAR(64)  NOT NULL,
+		    type    INT          NOT NULL,
+		    value   VARCHAR(255) NOT NULL,
+		    PRIMARY KEY (user_id, name),
+		    INDEX name_value_idx (name, value)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = v.client.ExecContext(ctx, `
+		CREATE TABLE user_label
+		(
+		    user_id VARCHAR(64) NOT NULL,
+		    label   VARCHAR(64) NOT NULL,
+		    PRIMARY KEY (user_id, label),
+		    INDEX label_idx (label)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792405075(client mysql.ClientContext) migrator.Migration {
+	return &version1792405075{
+		client: client,
+	}
+}
+
+type version1792405075 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792405075) Version() int64 {
+	return 1792405075
+}
+
+func (v version1792405075) Description() string {
+	return "Create 'role', 'role_permission' and 'user_role' tables"
+}
+
+func (v version1792405075) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		CREATE TABLE role
+		(
+		    name       VARCHAR(64) NOT NULL,
+		    created_at DATETIME    NOT NULL,
+		    updated_at DATETIME    NOT NULL,
+		    PRIMARY KEY (name)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = v.client.ExecContext(ctx, `
+		CREATE TABLE role_permission
+		(
+		    role       VARCHAR(64)  NOT NULL,
+		    permission VARCHAR(128) NOT NULL,
+		    PRIMARY KEY (role, permission)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = v.client.ExecContext(ctx, `
+		CREATE TABLE user_role
+		(
+		    user_id VARCHAR(64) NOT NULL,
+		    role    VARCHAR(64) NOT NULL,
+		    PRIMARY KEY (user_id, role),
+		    INDEX role_idx (role)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792405214(client mysql.ClientContext) migrator.Migration {
+	return &version1792405214{
+		client: client,
+	}
+}
+
+type version1792405214 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792405214) Version() int64 {
+	return 1792405214
+}
+
+func (v version1792405214) Description() string {
+	return "Create 'organization' and 'organization_member' tables"
+}
+
+func (v version1792405214) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		CREATE TABLE organization
+		(
+		    organization_id VARCHAR(64)  NOT NULL,
+		    name            VARCHAR(255) NOT NULL,
+		    created_at      DATETIME     NOT NULL,
+		    updated_at      DATETIME     NOT NULL,
+		    PRIMARY KEY (organization_id),
+		    UNIQUE INDEX name_idx (name)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = v.client.ExecContext(ctx, `
+		CREATE TABLE organization_member
+		(
+		    organization_id VARCHAR(64)  NOT NULL,
+		    user_id         VARCHAR(64)  NOT NULL,
+		    role            INT          NOT NULL,
+		    display_name    VARCHAR(255),
+		    joined_at       DATETIME     NOT NULL,
+		    PRIMARY KEY (organization_id, user_id),
+		    UNIQUE INDEX organization_display_name_idx (organization_id, display_name),
+		    INDEX user_id_idx (user_id)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792405363(client mysql.ClientContext) migrator.Migration {
+	return &version1792405363{
+		client: client,
+	}
+}
+
+type version1792405363 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792405363) Version() int64 {
+	return 1792405363
+}
+
+func (v version1792405363) Description() string {
+	return "Move blocked users without contacts to pending status"
+}
+
+func (v version1792405363) Up(ctx context.Context) error {
+	// blocked = 0, pending = 3, users without contacts were blocked by the system before pending status was introduced
+	_, err := v.client.ExecContext(ctx, `
+		UPDATE user SET status = 3
+		WHERE status = 0 AND user_id NOT IN (SELECT user_id FROM user_contact)
+	`)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792405444(client mysql.ClientContext) migrator.Migration {
+	return &version1792405444{
+		client: client,
+	}
+}
+
+type version1792405444 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792405444) Version() int64 {
+	return 1792405444
+}
+
+func (v version1792405444) Description() string {
+	return "Add status expiration columns to 'user' table"
+}
+
+func (v version1792405444) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		ALTER TABLE user
+		    ADD COLUMN status_expires_at DATETIME AFTER status,
+		    ADD COLUMN status_restore    INT      AFTER status_expires_at
+	`)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792405685(client mysql.ClientContext) migrator.Migration {
+	return &version1792405685{
+		client: client,
+	}
+}
+
+type version1792405685 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792405685) Version() int64 {
+	return 1792405685
+}
+
+func (v version1792405685) Description() string {
+	return "Add status source columns to 'user' table"
+}
+
+func (v version1792405685) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		ALTER TABLE user
+		    ADD COLUMN status_source         INT NOT NULL DEFAULT 0 AFTER status,
+		    ADD COLUMN status_restore_source INT AFTER status_restore
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// blocked users without contacts are moved to pending by previous migration and keep system source,
+	// users still blocked have contacts, so only admin could block them
+	_, err = v.client.ExecContext(ctx, `
+		UPDATE user SET status_source = 1 WHERE status = 0
+	`)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792405855(client mysql.ClientContext) migrator.Migration {
+	return &version1792405855{
+		client: client,
+	}
+}
+
+type version1792405855 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792405855) Version() int64 {
+	return 1792405855
+}
+
+func (v version1792405855) Description() string {
+	return "Create 'user_audit' table"
+}
+
+func (v version1792405855) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		CREATE TABLE user_audit
+		(
+		    audit_id       BIGINT       NOT NULL AUTO_INCREMENT,
+		    user_id        VARCHAR(64)  NOT NULL,
+		    actor          VARCHAR(255) NOT NULL,
+		    source         VARCHAR(64)  NOT NULL,
+		    correlation_id VARCHAR(255) NOT NULL,
+		    old_value      JSON,
+		    new_value      JSON         NOT NULL,
+		    created_at     DATETIME     NOT NULL,
+		    PRIMARY KEY (audit_id),
+		    INDEX user_id_idx (user_id, audit_id)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792405982(client mysql.ClientContext) migrator.Migration {
+	return &version1792405982{
+		client: client,
+	}
+}
+
+type version1792405982 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792405982) Version() int64 {
+	return 1792405982
+}
+
+func (v version1792405982) Description() string {
+	return "Create 'user_event' and 'user_snapshot' tables"
+}
+
+// Up stores payloads as text, JSON columns would normalize documents which are compared byte by byte
+func (v version1792405982) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		CREATE TABLE user_event
+		(
+		    user_id    VARCHAR(64) NOT NULL,
+		    version    BIGINT      NOT NULL,
+		    payload    MEDIUMTEXT  NOT NULL,
+		    created_at DATETIME    NOT NULL,
+		    PRIMARY KEY (user_id, version)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = v.client.ExecContext(ctx, `
+		CREATE TABLE user_snapshot
+		(
+		    user_id    VARCHAR(64) NOT NULL,
+		    version    BIGINT      NOT NULL,
+		    state      MEDIUMTEXT  NOT NULL,
+		    created_at DATETIME    NOT NULL,
+		    PRIMARY KEY (user_id)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	return errors.WithStack(err)
+}
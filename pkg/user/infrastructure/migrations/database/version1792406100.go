@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+
+	"userservice/pkg/user/domain/model"
+)
+
+func NewVersion1792406100(client mysql.ClientContext, emailNormalization model.EmailNormalization) migrator.Migration {
+	return &version1792406100{
+		client:             client,
+		emailNormalization: emailNormalization,
+	}
+}
+
+type version1792406100 struct {
+	client             mysql.ClientContext
+	emailNormalization model.EmailNormalization
+}
+
+func (v version1792406100) Version() int64 {
+	return 1792406100
+}
+
+func (v version1792406100) Description() string {
+	return "Add canonical login and contact values"
+}
+
+// Up fills canonical values with configured normalization, canonical columns use binary collation
+// as case of email local part is significant unless full email normalization is configured.
+// Logins and contacts that become equal after normalization fail migration, owners of them are decided by people
+func (v version1792406100) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		ALTER TABLE user
+		    ADD COLUMN login_canonical VARCHAR(32) COLLATE utf8mb4_bin NOT NULL DEFAULT '' AFTER login
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = v.client.ExecContext(ctx, `UPDATE user SET login_canonical = LOWER(TRIM(login))`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = v.checkUnique(ctx, "logins", `
+		SELECT login_canonical FROM user GROUP BY login_canonical HAVING COUNT(*) > 1 ORDER BY login_canonical
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = v.client.ExecContext(ctx, `ALTER TABLE user ADD UNIQUE INDEX login_canonical_idx (login_canonical)`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = v.client.ExecContext(ctx, `
+		ALTER TABLE user_contact
+		    ADD COLUMN canonical_value VARCHAR(255) COLLATE utf8mb4_bin NOT NULL DEFAULT '' AFTER value
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = v.client.ExecContext(ctx, `UPDATE user_contact SET canonical_value = `+v.canonicalValue())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = v.checkUnique(ctx, "contacts", `
+		SELECT CONCAT(type, ':', canonical_value) FROM user_contact
+		GROUP BY type, canonical_value HAVING COUNT(*) > 1 ORDER BY type, canonical_value
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = v.client.ExecContext(ctx, `
+		ALTER TABLE user_contact
+		    DROP INDEX type_value_idx,
+		    DROP PRIMARY KEY,
+		    ADD PRIMARY KEY (user_id, type, canonical_value),
+		    ADD UNIQUE INDEX type_canonical_value_idx (type, canonical_value)
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = v.client.ExecContext(ctx, `ALTER TABLE user_contact_verification MODIFY value VARCHAR(255) COLLATE utf8mb4_bin NOT NULL`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// verifications of values equal after normalization are dropped, new codes have to be requested for them
+	_, err = v.client.ExecContext(ctx, `UPDATE IGNORE user_contact_verification SET value = `+v.canonicalValue())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = v.client.ExecContext(ctx, `DELETE FROM user_contact_verification WHERE value <> `+v.canonicalValue())
+	return errors.WithStack(err)
+}
+
+// canonicalValue is SQL expression of canonical form of value column, it follows normalization of domain model.
+// 0 - email, 1 - telegram contact types, domain of email starts after its last '@'
+func (v version1792406100) canonicalValue() string {
+	email := `CONCAT(
+		LEFT(value, CHAR_LENGTH(value) - CHAR_LENGTH(SUBSTRING_INDEX(value, '@', -1))),
+		LOWER(SUBSTRING_INDEX(value, '@', -1))
+	)`
+	if v.emailNormalization == model.EmailCaseInsensitive {
+		email = `LOWER(value)`
+	}
+	return `CASE type
+		WHEN 0 THEN ` + email + `
+		WHEN 1 THEN LOWER(TRIM(LEADING '@' FROM value))
+		ELSE value
+	END`
+}
+
+// checkUnique fails when query finds values that are going to violate unique index
+func (v version1792406100) checkUnique(ctx context.Context, name, query string) error {
+	var duplicates []string
+	err := v.client.SelectContext(ctx, &duplicates, query)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(duplicates) > 0 {
+		return errors.Errorf("%s are not unique after normalization, resolve them before migration: %s", name, strings.Join(duplicates, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792406175(client mysql.ClientContext) migrator.Migration {
+	return &version1792406175{
+		client: client,
+	}
+}
+
+type version1792406175 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792406175) Version() int64 {
+	return 1792406175
+}
+
+func (v version1792406175) Description() string {
+	return "Add 'merged_into' column to 'user' table"
+}
+
+func (v version1792406175) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `ALTER TABLE user ADD COLUMN merged_into VARCHAR(64) AFTER deleted_at`)
+	return errors.WithStack(err)
+}
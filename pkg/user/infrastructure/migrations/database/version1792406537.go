@@ -0,0 +1,32 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792406537(client mysql.ClientContext) migrator.Migration {
+	return &version1792406537{
+		client: client,
+	}
+}
+
+type version1792406537 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792406537) Version() int64 {
+	return 1792406537
+}
+
+func (v version1792406537) Description() string {
+	return "Add 'version' column to 'user' table"
+}
+
+func (v version1792406537) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `ALTER TABLE user ADD COLUMN version BIGINT NOT NULL DEFAULT 0 AFTER merged_into`)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792406678(client mysql.ClientContext) migrator.Migration {
+	return &version1792406678{
+		client: client,
+	}
+}
+
+type version1792406678 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792406678) Version() int64 {
+	return 1792406678
+}
+
+func (v version1792406678) Description() string {
+	return "Create 'user_credentials' table"
+}
+
+func (v version1792406678) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		CREATE TABLE user_credentials
+		(
+		    user_id             VARCHAR(64)  NOT NULL,
+		    password_hash       VARCHAR(255) NOT NULL,
+		    failed_attempts     INT          NOT NULL DEFAULT 0,
+		    locked_until        DATETIME,
+		    password_changed_at DATETIME     NOT NULL,
+		    PRIMARY KEY (user_id)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	return errors.WithStack(err)
+}
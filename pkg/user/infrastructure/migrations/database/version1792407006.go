@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792407006(client mysql.ClientContext) migrator.Migration {
+	return &version1792407006{
+		client: client,
+	}
+}
+
+type version1792407006 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792407006) Version() int64 {
+	return 1792407006
+}
+
+func (v version1792407006) Description() string {
+	return "Create 'user_api_token' and 'user_api_token_scope' tables"
+}
+
+func (v version1792407006) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		CREATE TABLE user_api_token
+		(
+		    token_id     VARCHAR(64)  NOT NULL,
+		    user_id      VARCHAR(64)  NOT NULL,
+		    name         VARCHAR(255) NOT NULL,
+		    token_hash   VARCHAR(64)  NOT NULL,
+		    expires_at   DATETIME,
+		    last_used_at DATETIME,
+		    created_at   DATETIME     NOT NULL,
+		    PRIMARY KEY (token_id),
+		    UNIQUE INDEX token_hash_idx (token_hash),
+		    UNIQUE INDEX user_id_name_idx (user_id, name)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = v.client.ExecContext(ctx, `
+		CREATE TABLE user_api_token_scope
+		(
+		    token_id VARCHAR(64)  NOT NULL,
+		    user_id  VARCHAR(64)  NOT NULL,
+		    scope    VARCHAR(128) NOT NULL,
+		    PRIMARY KEY (token_id, scope),
+		    INDEX user_id_idx (user_id)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792407227(client mysql.ClientContext) migrator.Migration {
+	return &version1792407227{
+		client: client,
+	}
+}
+
+type version1792407227 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792407227) Version() int64 {
+	return 1792407227
+}
+
+func (v version1792407227) Description() string {
+	return "Create 'user_totp' and 'user_totp_recovery_code' tables"
+}
+
+func (v version1792407227) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		CREATE TABLE user_totp
+		(
+		    user_id          VARCHAR(64)    NOT NULL,
+		    encrypted_secret VARBINARY(255) NOT NULL,
+		    confirmed_at     DATETIME,
+		    last_used_step   BIGINT         NOT NULL DEFAULT 0,
+		    failed_attempts  INT            NOT NULL DEFAULT 0,
+		    locked_until     DATETIME,
+		    created_at       DATETIME       NOT NULL,
+		    PRIMARY KEY (user_id)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = v.client.ExecContext(ctx, `
+		CREATE TABLE user_totp_recovery_code
+		(
+		    user_id   VARCHAR(64) NOT NULL,
+		    code_hash VARCHAR(64) NOT NULL,
+		    PRIMARY KEY (user_id, code_hash)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792407365(client mysql.ClientContext) migrator.Migration {
+	return &version1792407365{
+		client: client,
+	}
+}
+
+type version1792407365 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792407365) Version() int64 {
+	return 1792407365
+}
+
+func (v version1792407365) Description() string {
+	return "Create 'user_identity' table"
+}
+
+func (v version1792407365) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		CREATE TABLE user_identity
+		(
+		    user_id   VARCHAR(64)  NOT NULL,
+		    issuer    VARCHAR(255) NOT NULL COLLATE utf8mb4_bin,
+		    subject   VARCHAR(255) NOT NULL COLLATE utf8mb4_bin,
+		    linked_at DATETIME     NOT NULL,
+		    PRIMARY KEY (issuer, subject),
+		    INDEX user_id_idx (user_id)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	return errors.WithStack(err)
+}
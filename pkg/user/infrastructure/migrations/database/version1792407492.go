@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792407492(client mysql.ClientContext) migrator.Migration {
+	return &version1792407492{
+		client: client,
+	}
+}
+
+type version1792407492 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792407492) Version() int64 {
+	return 1792407492
+}
+
+func (v version1792407492) Description() string {
+	return "Create 'user_notification_preference' table"
+}
+
+func (v version1792407492) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		CREATE TABLE user_notification_preference
+		(
+		    user_id  VARCHAR(64) NOT NULL,
+		    channel  INT         NOT NULL,
+		    category VARCHAR(64) NOT NULL,
+		    enabled  TINYINT(1)  NOT NULL,
+		    PRIMARY KEY (user_id, channel, category)
+		)
+		    ENGINE = InnoDB
+		    CHARACTER SET = utf8mb4
+		    COLLATE utf8mb4_unicode_ci
+	`)
+	return errors.WithStack(err)
+}
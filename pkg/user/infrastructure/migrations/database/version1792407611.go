@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792407611(client mysql.ClientContext) migrator.Migration {
+	return &version1792407611{
+		client: client,
+	}
+}
+
+type version1792407611 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792407611) Version() int64 {
+	return 1792407611
+}
+
+func (v version1792407611) Description() string {
+	return "Add 'tenant_id' to users and organizations, make logins, contacts, identities and organization names unique within tenant"
+}
+
+// Up assigns existing users and organizations to default tenant. Tenant goes last in unique indexes of contacts and identities
+// to keep lookups without tenant indexed, logins and organization names are looked up only within tenant.
+// Audit keeps tenant of its own as history of user is kept after user deletion
+func (v version1792407611) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+		ALTER TABLE user
+		    ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' AFTER user_id,
+		    DROP INDEX login_canonical_idx,
+		    ADD UNIQUE INDEX tenant_login_canonical_idx (tenant_id, login_canonical)
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = v.client.ExecContext(ctx, `
+		ALTER TABLE user_contact
+		    ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' AFTER user_id,
+		    DROP INDEX type_canonical_value_idx,
+		    ADD UNIQUE INDEX type_canonical_value_tenant_idx (type, canonical_value, tenant_id)
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = v.client.ExecContext(ctx, `
+		ALTER TABLE user_identity
+		    ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' AFTER user_id,
+		    DROP PRIMARY KEY,
+		    ADD PRIMARY KEY (issuer, subject, tenant_id)
+	`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = v.client.ExecContext(ctx, `ALTER TABLE user_audit ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' AFTER user_id`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = v.client.ExecContext(ctx, `
+		ALTER TABLE organization
+		    ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' AFTER organization_id,
+		    DROP INDEX name_idx,
+		    ADD UNIQUE INDEX tenant_name_idx (tenant_id, name)
+	`)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,76 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	appmodel "userservice/pkg/user/application/model"
+	"userservice/pkg/user/application/query"
+	"userservice/pkg/user/domain/model"
+)
+
+func NewOrganizationQueryService(client mysql.ClientContext) query.OrganizationQueryService {
+	return &organizationQueryService{
+		client: client,
+	}
+}
+
+type organizationQueryService struct {
+	client mysql.ClientContext
+}
+
+func (o *organizationQueryService) FindOrganization(ctx context.Context, organizationID uuid.UUID) (*appmodel.Organization, error) {
+	organization := struct {
+		OrganizationID uuid.UUID `db:"organization_id"`
+		Name           string    `db:"name"`
+	}{}
+	tenantQuery, tenantArgs := tenantCondition(ctx, "tenant_id")
+	err := o.client.GetContext(
+		ctx,
+		&organization,
+		`SELECT organization_id, name FROM organization WHERE organization_id = ? AND `+tenantQuery,
+		append([]interface{}{organizationID}, tenantArgs...)...,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.WithStack(model.ErrOrganizationNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	var members []struct {
+		UserID      uuid.UUID        `db:"user_id"`
+		Role        int              `db:"role"`
+		DisplayName sql.Null[string] `db:"display_name"`
+		JoinedAt    time.Time        `db:"joined_at"`
+	}
+	err = o.client.SelectContext(
+		ctx,
+		&members,
+		`SELECT user_id, role, display_name, joined_at FROM organization_member WHERE organization_id = ? ORDER BY joined_at, user_id`,
+		organizationID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := &appmodel.Organization{
+		OrganizationID: organization.OrganizationID,
+		Name:           organization.Name,
+		Members:        make([]appmodel.Member, 0, len(members)),
+	}
+	for _, member := range members {
+		result.Members = append(result.Members, appmodel.Member{
+			UserID:      member.UserID,
+			Role:        member.Role,
+			DisplayName: fromSQLNull(member.DisplayName),
+			JoinedAt:    member.JoinedAt,
+		})
+	}
+	return result, nil
+}
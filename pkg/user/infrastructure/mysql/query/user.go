@@ -3,6 +3,8 @@ package query
 import (
 	"context"
 	"database/sql"
+	"strings"
+	"time"
 
 	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
 	"github.com/google/uuid"
@@ -10,33 +12,73 @@ import (
 
 	appmodel "userservice/pkg/user/application/model"
 	"userservice/pkg/user/application/query"
+	"userservice/pkg/user/application/service"
 	"userservice/pkg/user/domain/model"
 )
 
-func NewUserQueryService(client mysql.ClientContext) query.UserQueryService {
+func NewUserQueryService(client mysql.ClientContext, attributeSchema model.AttributeSchema) query.UserQueryService {
 	return &userQueryService{
-		client: client,
+		client:          client,
+		attributeSchema: attributeSchema,
 	}
 }
 
 type userQueryService struct {
-	client mysql.ClientContext
+	client          mysql.ClientContext
+	attributeSchema model.AttributeSchema
 }
 
-func (u *userQueryService) FindUser(ctx context.Context, userID uuid.UUID) (*appmodel.User, error) {
-	user := struct {
-		UserID   uuid.UUID        `db:"user_id"`
-		Status   int              `db:"status"`
-		Login    string           `db:"login"`
-		Email    sql.Null[string] `db:"email"`
-		Telegram sql.Null[string] `db:"telegram"`
-	}{}
+type userRow struct {
+	UserID          uuid.UUID           `db:"user_id"`
+	TenantID        string              `db:"tenant_id"`
+	Status          int                 `db:"status"`
+	StatusSource    int                 `db:"status_source"`
+	StatusExpiresAt sql.Null[time.Time] `db:"status_expires_at"`
+	Login           string              `db:"login"`
+	DisplayName     sql.Null[string]    `db:"display_name"`
+	Locale          sql.Null[string]    `db:"locale"`
+	Timezone        sql.Null[string]    `db:"timezone"`
+	AvatarURL       sql.Null[string]    `db:"avatar_url"`
+	MergedInto      sql.Null[uuid.UUID] `db:"merged_into"`
+}
 
+const userColumns = `user_id, tenant_id, status, status_source, status_expires_at, login, display_name, locale, timezone, avatar_url, merged_into`
+
+func (u *userQueryService) FindUser(ctx context.Context, userID uuid.UUID) (*appmodel.User, error) {
+	tenantQuery, tenantArgs := tenantCondition(ctx, "tenant_id")
+	var user userRow
 	err := u.client.GetContext(
 		ctx,
 		&user,
-		`SELECT user_id, status, login, email, telegram FROM user WHERE user_id = ?`,
-		userID,
+		`SELECT `+userColumns+` FROM user WHERE user_id = ? AND `+tenantQuery,
+		append([]interface{}{userID}, tenantArgs...)...,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.WithStack(model.ErrUserNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	users, err := u.loadUsers(ctx, []userRow{user})
+	if err != nil {
+		return nil, err
+	}
+	return &users[0], nil
+}
+
+func (u *userQueryService) FindUserByIdentity(ctx context.Context, issuer, subject string) (*appmodel.User, error) {
+	identity, err := model.NewIdentitySpec(issuer, subject)
+	if err != nil {
+		return nil, err
+	}
+	tenantQuery, tenantArgs := tenantCondition(ctx, "tenant_id")
+	var user userRow
+	err = u.client.GetContext(
+		ctx,
+		&user,
+		`SELECT `+userColumns+` FROM user WHERE user_id IN (SELECT user_id FROM user_identity WHERE issuer = ? AND subject = ?) AND status != ? AND `+tenantQuery,
+		append([]interface{}{identity.Issuer, identity.Subject, model.Deleted}, tenantArgs...)...,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -45,13 +87,433 @@ func (u *userQueryService) FindUser(ctx context.Context, userID uuid.UUID) (*app
 		return nil, errors.WithStack(err)
 	}
 
-	return &appmodel.User{
-		UserID:   user.UserID,
-		Status:   user.Status,
-		Login:    user.Login,
-		Email:    fromSQLNull(user.Email),
-		Telegram: fromSQLNull(user.Telegram),
-	}, nil
+	users, err := u.loadUsers(ctx, []userRow{user})
+	if err != nil {
+		return nil, err
+	}
+	return &users[0], nil
+}
+
+func (u *userQueryService) ListUsers(ctx context.Context, spec query.ListSpec) ([]appmodel.User, error) {
+	tenantQuery, args := tenantCondition(ctx, "tenant_id")
+	parts := []string{tenantQuery}
+	if spec.AfterUserID != nil {
+		parts = append(parts, "user_id > ?")
+		args = append(args, *spec.AfterUserID)
+	}
+	for _, label := range spec.Labels {
+		parts = append(parts, "user_id IN (SELECT user_id FROM user_label WHERE label = ?)")
+		args = append(args, strings.ToLower(label))
+	}
+	if spec.OrganizationID != nil {
+		parts = append(parts, "user_id IN (SELECT user_id FROM organization_member WHERE organization_id = ?)")
+		args = append(args, *spec.OrganizationID)
+	}
+	for key, value := range spec.Attributes {
+		attribute, err := u.attributeSchema.NewAttribute(key, value)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, "user_id IN (SELECT user_id FROM user_attribute WHERE name = ? AND value = ?)")
+		args = append(args, attribute.Key, attribute.Value)
+	}
+	args = append(args, spec.Limit)
+
+	var users []userRow
+	err := u.client.SelectContext(
+		ctx,
+		&users,
+		`SELECT `+userColumns+` FROM user WHERE `+strings.Join(parts, " AND ")+` ORDER BY user_id LIMIT ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return u.loadUsers(ctx, users)
+}
+
+func (u *userQueryService) CheckPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
+	tenantQuery, tenantArgs := tenantCondition(ctx, "tenant_id")
+	result := struct {
+		Status  int  `db:"status"`
+		Granted bool `db:"granted"`
+	}{}
+	err := u.client.GetContext(
+		ctx,
+		&result,
+		`
+	SELECT status, EXISTS(
+		SELECT 1 FROM user_role ur
+		INNER JOIN role_permission rp ON rp.role = ur.role
+		WHERE ur.user_id = user.user_id AND rp.permission = ?
+	) AS granted
+	FROM user WHERE user_id = ? AND `+tenantQuery,
+		append([]interface{}{strings.ToLower(permission), userID}, tenantArgs...)...,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, errors.WithStack(model.ErrUserNotFound)
+		}
+		return false, errors.WithStack(err)
+	}
+	return result.Granted && model.UserStatus(result.Status) == model.Active, nil
+}
+
+func (u *userQueryService) GetUserHistory(ctx context.Context, spec query.HistorySpec) ([]appmodel.AuditRecord, error) {
+	tenantQuery, tenantArgs := tenantCondition(ctx, "tenant_id")
+	query := `SELECT audit_id, user_id, actor, source, correlation_id, old_value, new_value, created_at FROM user_audit WHERE user_id = ? AND ` + tenantQuery
+	args := append([]interface{}{spec.UserID}, tenantArgs...)
+	if spec.AfterAuditID != nil {
+		query += ` AND audit_id > ?`
+		args = append(args, *spec.AfterAuditID)
+	}
+	args = append(args, spec.Limit)
+
+	var rows []struct {
+		AuditID       int64            `db:"audit_id"`
+		UserID        uuid.UUID        `db:"user_id"`
+		Actor         string           `db:"actor"`
+		Source        string           `db:"source"`
+		CorrelationID string           `db:"correlation_id"`
+		OldValue      sql.Null[string] `db:"old_value"`
+		NewValue      string           `db:"new_value"`
+		CreatedAt     time.Time        `db:"created_at"`
+	}
+	err := u.client.SelectContext(ctx, &rows, query+` ORDER BY audit_id LIMIT ?`, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	records := make([]appmodel.AuditRecord, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, appmodel.AuditRecord{
+			AuditID:       row.AuditID,
+			UserID:        row.UserID,
+			Actor:         row.Actor,
+			Source:        row.Source,
+			CorrelationID: row.CorrelationID,
+			OldValue:      fromSQLNull(row.OldValue),
+			NewValue:      row.NewValue,
+			CreatedAt:     row.CreatedAt,
+		})
+	}
+	return records, nil
+}
+
+func (u *userQueryService) ListAPITokens(ctx context.Context, userID uuid.UUID) ([]appmodel.APIToken, error) {
+	tenantQuery, tenantArgs := tenantCondition(ctx, "u.tenant_id")
+	var rows []struct {
+		TokenID    uuid.UUID           `db:"token_id"`
+		Name       string              `db:"name"`
+		ExpiresAt  sql.Null[time.Time] `db:"expires_at"`
+		LastUsedAt sql.Null[time.Time] `db:"last_used_at"`
+		CreatedAt  time.Time           `db:"created_at"`
+	}
+	err := u.client.SelectContext(
+		ctx,
+		&rows,
+		`
+	SELECT t.token_id, t.name, t.expires_at, t.last_used_at, t.created_at FROM user_api_token t
+	INNER JOIN user u ON u.user_id = t.user_id
+	WHERE t.user_id = ? AND `+tenantQuery+` ORDER BY t.token_id
+	`,
+		append([]interface{}{userID}, tenantArgs...)...,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var scopes []struct {
+		TokenID uuid.UUID `db:"token_id"`
+		Scope   string    `db:"scope"`
+	}
+	err = u.client.SelectContext(
+		ctx,
+		&scopes,
+		`SELECT token_id, scope FROM user_api_token_scope WHERE user_id = ? ORDER BY scope`,
+		userID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	tokenScopes := make(map[uuid.UUID][]string, len(rows))
+	for _, scope := range scopes {
+		tokenScopes[scope.TokenID] = append(tokenScopes[scope.TokenID], scope.Scope)
+	}
+
+	tokens := make([]appmodel.APIToken, 0, len(rows))
+	for _, row := range rows {
+		tokens = append(tokens, appmodel.APIToken{
+			TokenID:    row.TokenID,
+			Name:       row.Name,
+			Scopes:     tokenScopes[row.TokenID],
+			ExpiresAt:  fromSQLNull(row.ExpiresAt),
+			LastUsedAt: fromSQLNull(row.LastUsedAt),
+			CreatedAt:  row.CreatedAt,
+		})
+	}
+	return tokens, nil
+}
+
+// loadUsers fetches data stored in separate tables and builds users in order of rows
+func (u *userQueryService) loadUsers(ctx context.Context, users []userRow) ([]appmodel.User, error) {
+	userIDs := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.UserID)
+	}
+	contacts, err := u.findContacts(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := u.findAttributes(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := u.findLabels(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	notificationPreferences, err := u.findNotificationPreferences(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := u.findRoles(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	memberships, err := u.findMemberships(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]appmodel.User, 0, len(users))
+	for _, user := range users {
+		result = append(result, appmodel.User{
+			UserID:                  user.UserID,
+			TenantID:                user.TenantID,
+			Status:                  user.Status,
+			StatusSource:            user.StatusSource,
+			StatusExpiresAt:         fromSQLNull(user.StatusExpiresAt),
+			Login:                   user.Login,
+			DisplayName:             fromSQLNull(user.DisplayName),
+			Locale:                  fromSQLNull(user.Locale),
+			Timezone:                fromSQLNull(user.Timezone),
+			AvatarURL:               fromSQLNull(user.AvatarURL),
+			MergedInto:              fromSQLNull(user.MergedInto),
+			Contacts:                contacts[user.UserID],
+			Attributes:              attributes[user.UserID],
+			Labels:                  labels[user.UserID],
+			NotificationPreferences: notificationPreferences[user.UserID],
+			Roles:                   roles[user.UserID],
+			Memberships:             memberships[user.UserID],
+		})
+	}
+	return result, nil
+}
+
+func (u *userQueryService) findContacts(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]appmodel.Contact, error) {
+	result := make(map[uuid.UUID][]appmodel.Contact, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var contacts []struct {
+		UserID     uuid.UUID           `db:"user_id"`
+		Type       int                 `db:"type"`
+		Value      string              `db:"value"`
+		Primary    bool                `db:"is_primary"`
+		VerifiedAt sql.Null[time.Time] `db:"verified_at"`
+	}
+	placeholders, args := inArgs(userIDs)
+	err := u.client.SelectContext(
+		ctx,
+		&contacts,
+		`SELECT user_id, type, value, is_primary, verified_at FROM user_contact WHERE user_id IN (`+placeholders+`) ORDER BY type, is_primary DESC, value`,
+		args...,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for _, contact := range contacts {
+		result[contact.UserID] = append(result[contact.UserID], appmodel.Contact{
+			Type:       contact.Type,
+			Value:      contact.Value,
+			Primary:    contact.Primary,
+			VerifiedAt: fromSQLNull(contact.VerifiedAt),
+		})
+	}
+	return result, nil
+}
+
+func (u *userQueryService) findAttributes(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]map[string]string, error) {
+	result := make(map[uuid.UUID]map[string]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var attributes []struct {
+		UserID uuid.UUID `db:"user_id"`
+		Key    string    `db:"name"`
+		Value  string    `db:"value"`
+	}
+	placeholders, args := inArgs(userIDs)
+	err := u.client.SelectContext(
+		ctx,
+		&attributes,
+		`SELECT user_id, name, value FROM user_attribute WHERE user_id IN (`+placeholders+`)`,
+		args...,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for _, attribute := range attributes {
+		if result[attribute.UserID] == nil {
+			result[attribute.UserID] = make(map[string]string)
+		}
+		result[attribute.UserID][attribute.Key] = attribute.Value
+	}
+	return result, nil
+}
+
+func (u *userQueryService) findLabels(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	result := make(map[uuid.UUID][]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var labels []struct {
+		UserID uuid.UUID `db:"user_id"`
+		Label  string    `db:"label"`
+	}
+	placeholders, args := inArgs(userIDs)
+	err := u.client.SelectContext(
+		ctx,
+		&labels,
+		`SELECT user_id, label FROM user_label WHERE user_id IN (`+placeholders+`) ORDER BY label`,
+		args...,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for _, label := range labels {
+		result[label.UserID] = append(result[label.UserID], label.Label)
+	}
+	return result, nil
+}
+
+func (u *userQueryService) findNotificationPreferences(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]appmodel.NotificationPreference, error) {
+	result := make(map[uuid.UUID][]appmodel.NotificationPreference, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var preferences []struct {
+		UserID   uuid.UUID `db:"user_id"`
+		Channel  int       `db:"channel"`
+		Category string    `db:"category"`
+		Enabled  bool      `db:"enabled"`
+	}
+	placeholders, args := inArgs(userIDs)
+	err := u.client.SelectContext(
+		ctx,
+		&preferences,
+		`SELECT user_id, channel, category, enabled FROM user_notification_preference WHERE user_id IN (`+placeholders+`) ORDER BY channel, category COLLATE utf8mb4_bin`,
+		args...,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for _, preference := range preferences {
+		result[preference.UserID] = append(result[preference.UserID], appmodel.NotificationPreference{
+			Channel:  preference.Channel,
+			Category: preference.Category,
+			Enabled:  preference.Enabled,
+		})
+	}
+	return result, nil
+}
+
+func (u *userQueryService) findRoles(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	result := make(map[uuid.UUID][]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var roles []struct {
+		UserID uuid.UUID `db:"user_id"`
+		Role   string    `db:"role"`
+	}
+	placeholders, args := inArgs(userIDs)
+	err := u.client.SelectContext(
+		ctx,
+		&roles,
+		`SELECT user_id, role FROM user_role WHERE user_id IN (`+placeholders+`) ORDER BY role`,
+		args...,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for _, role := range roles {
+		result[role.UserID] = append(result[role.UserID], role.Role)
+	}
+	return result, nil
+}
+
+func (u *userQueryService) findMemberships(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]appmodel.Membership, error) {
+	result := make(map[uuid.UUID][]appmodel.Membership, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var memberships []struct {
+		UserID         uuid.UUID        `db:"user_id"`
+		OrganizationID uuid.UUID        `db:"organization_id"`
+		Role           int              `db:"role"`
+		DisplayName    sql.Null[string] `db:"display_name"`
+	}
+	placeholders, args := inArgs(userIDs)
+	err := u.client.SelectContext(
+		ctx,
+		&memberships,
+		`SELECT user_id, organization_id, role, display_name FROM organization_member WHERE user_id IN (`+placeholders+`) ORDER BY joined_at, organization_id`,
+		args...,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for _, membership := range memberships {
+		result[membership.UserID] = append(result[membership.UserID], appmodel.Membership{
+			OrganizationID: membership.OrganizationID,
+			Role:           membership.Role,
+			DisplayName:    fromSQLNull(membership.DisplayName),
+		})
+	}
+	return result, nil
+}
+
+// tenantCondition restricts query to users and organizations of tenant of context,
+// users of all tenants are visible to callers without tenant, e.g. workflows
+func tenantCondition(ctx context.Context, column string) (string, []interface{}) {
+	tenantID, ok := service.TenantFromContext(ctx)
+	if !ok {
+		return "1 = 1", nil
+	}
+	return column + " = ?", []interface{}{tenantID}
+}
+
+func inArgs[T any](values []T) (placeholders string, args []interface{}) {
+	args = make([]interface{}, 0, len(values))
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", "), args
 }
 
 func fromSQLNull[T any](v sql.Null[T]) *T {
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"userservice/pkg/user/domain/model"
+)
+
+func NewOrganizationRepository(ctx context.Context, client mysql.ClientContext) model.OrganizationRepository {
+	return &organizationRepository{
+		ctx:    ctx,
+		client: client,
+	}
+}
+
+type organizationRepository struct {
+	ctx    context.Context
+	client mysql.ClientContext
+}
+
+func (o *organizationRepository) NextID() (uuid.UUID, error) {
+	return uuid.NewV7()
+}
+
+func (o *organizationRepository) Store(organization model.Organization) error {
+	_, err := o.client.ExecContext(o.ctx,
+		`
+	INSERT INTO organization (organization_id, tenant_id, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		name=VALUES(name),
+	    updated_at=VALUES(updated_at)
+	`,
+		organization.OrganizationID,
+		organization.TenantID,
+		organization.Name,
+		organization.CreatedAt,
+		organization.UpdatedAt,
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return o.storeMembers(organization.OrganizationID, organization.Members)
+}
+
+func (o *organizationRepository) Find(spec model.OrganizationFindSpec) (*model.Organization, error) {
+	organization := struct {
+		OrganizationID uuid.UUID `db:"organization_id"`
+		TenantID       string    `db:"tenant_id"`
+		Name           string    `db:"name"`
+		CreatedAt      time.Time `db:"created_at"`
+		UpdatedAt      time.Time `db:"updated_at"`
+	}{}
+	query, args := o.buildSpecArgs(spec)
+
+	err := o.client.GetContext(
+		o.ctx,
+		&organization,
+		`SELECT organization_id, tenant_id, name, created_at, updated_at FROM organization WHERE `+query,
+		args...,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.WithStack(model.ErrOrganizationNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	members, err := o.findMembers(organization.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Organization{
+		OrganizationID: organization.OrganizationID,
+		TenantID:       organization.TenantID,
+		Name:           organization.Name,
+		Members:        members,
+		CreatedAt:      organization.CreatedAt,
+		UpdatedAt:      organization.UpdatedAt,
+	}, nil
+}
+
+func (o *organizationRepository) FindByMember(userID uuid.UUID) ([]model.Organization, error) {
+	var organizationIDs []uuid.UUID
+	err := o.client.SelectContext(
+		o.ctx,
+		&organizationIDs,
+		`SELECT organization_id FROM organization_member WHERE user_id = ? ORDER BY organization_id`,
+		userID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	organizations := make([]model.Organization, 0, len(organizationIDs))
+	for _, organizationID := range organizationIDs {
+		organization, err := o.Find(model.OrganizationFindSpec{OrganizationID: &organizationID})
+		if err != nil {
+			return nil, err
+		}
+		organizations = append(organizations, *organization)
+	}
+	return organizations, nil
+}
+
+func (o *organizationRepository) storeMembers(organizationID uuid.UUID, members []model.Member) error {
+	_, err := o.client.ExecContext(o.ctx, `DELETE FROM organization_member WHERE organization_id = ?`, organizationID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(members))
+	args := make([]interface{}, 0, len(members)*5)
+	for _, member := range members {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?)")
+		args = append(args, organizationID, member.UserID, member.Role, toSQLNull(member.DisplayName), member.JoinedAt)
+	}
+	_, err = o.client.ExecContext(o.ctx,
+		`INSERT INTO organization_member (organization_id, user_id, role, display_name, joined_at) VALUES `+strings.Join(placeholders, ", "),
+		args...,
+	)
+	return errors.WithStack(err)
+}
+
+func (o *organizationRepository) findMembers(organizationID uuid.UUID) ([]model.Member, error) {
+	var members []struct {
+		UserID      uuid.UUID        `db:"user_id"`
+		Role        int              `db:"role"`
+		DisplayName sql.Null[string] `db:"display_name"`
+		JoinedAt    time.Time        `db:"joined_at"`
+	}
+	err := o.client.SelectContext(
+		o.ctx,
+		&members,
+		`SELECT user_id, role, display_name, joined_at FROM organization_member WHERE organization_id = ? ORDER BY joined_at, user_id`,
+		organizationID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]model.Member, 0, len(members))
+	for _, member := range members {
+		result = append(result, model.Member{
+			UserID:      member.UserID,
+			Role:        model.MembershipRole(member.Role),
+			DisplayName: fromSQLNull(member.DisplayName),
+			JoinedAt:    member.JoinedAt,
+		})
+	}
+	return result, nil
+}
+
+func (o *organizationRepository) buildSpecArgs(spec model.OrganizationFindSpec) (query string, args []interface{}) {
+	var parts []string
+	if spec.TenantID != nil {
+		parts = append(parts, "tenant_id = ?")
+		args = append(args, *spec.TenantID)
+	}
+	if spec.OrganizationID != nil {
+		parts = append(parts, "organization_id = ?")
+		args = append(args, *spec.OrganizationID)
+	}
+	if spec.Name != nil {
+		parts = append(parts, "name = ?")
+		args = append(args, *spec.Name)
+	}
+	return strings.Join(parts, " AND "), args
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+
+	"userservice/pkg/user/domain/model"
+)
+
+func NewRoleRepository(ctx context.Context, client mysql.ClientContext) model.RoleRepository {
+	return &roleRepository{
+		ctx:    ctx,
+		client: client,
+	}
+}
+
+type roleRepository struct {
+	ctx    context.Context
+	client mysql.ClientContext
+}
+
+func (r *roleRepository) Store(role model.Role) error {
+	_, err := r.client.ExecContext(r.ctx,
+		`
+	INSERT INTO role (name, created_at, updated_at) VALUES (?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		updated_at=VALUES(updated_at)
+	`,
+		role.Name,
+		role.CreatedAt,
+		role.UpdatedAt,
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = r.client.ExecContext(r.ctx, `DELETE FROM role_permission WHERE role = ?`, role.Name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(role.Permissions) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(role.Permissions))
+	args := make([]interface{}, 0, len(role.Permissions)*2)
+	for _, permission := range role.Permissions {
+		placeholders = append(placeholders, "(?, ?)")
+		args = append(args, role.Name, permission)
+	}
+	_, err = r.client.ExecContext(r.ctx,
+		`INSERT INTO role_permission (role, permission) VALUES `+strings.Join(placeholders, ", "),
+		args...,
+	)
+	return errors.WithStack(err)
+}
+
+func (r *roleRepository) Find(name string) (*model.Role, error) {
+	role := struct {
+		Name      string    `db:"name"`
+		CreatedAt time.Time `db:"created_at"`
+		UpdatedAt time.Time `db:"updated_at"`
+	}{}
+	err := r.client.GetContext(
+		r.ctx,
+		&role,
+		`SELECT name, created_at, updated_at FROM role WHERE name = ?`,
+		name,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.WithStack(model.ErrRoleNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	var permissions []string
+	err = r.client.SelectContext(
+		r.ctx,
+		&permissions,
+		`SELECT permission FROM role_permission WHERE role = ? ORDER BY permission`,
+		name,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &model.Role{
+		Name:        role.Name,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}, nil
+}
@@ -30,46 +30,132 @@ func (u *userRepository) NextID() (uuid.UUID, error) {
 }
 
 func (u *userRepository) Store(user model.User) error {
-	_, err := u.client.ExecContext(u.ctx,
+	old, err := u.Find(model.FindSpec{UserID: &user.UserID})
+	if err != nil && !errors.Is(err, model.ErrUserNotFound) {
+		return err
+	}
+
+	var (
+		statusExpiresAt     sql.Null[time.Time]
+		statusRestore       sql.Null[int]
+		statusRestoreSource sql.Null[int]
+	)
+	if user.StatusExpiration != nil {
+		statusExpiresAt = sql.Null[time.Time]{V: user.StatusExpiration.ExpiresAt, Valid: true}
+		statusRestore = sql.Null[int]{V: int(user.StatusExpiration.RestoreStatus), Valid: true}
+		statusRestoreSource = sql.Null[int]{V: int(user.StatusExpiration.RestoreStatusSource), Valid: true}
+	}
+	_, err = u.client.ExecContext(u.ctx,
 		`
-	INSERT INTO user (user_id, status, login, email, telegram, created_at, updated_at, deleted_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	INSERT INTO user (user_id, tenant_id, status, status_source, status_expires_at, status_restore, status_restore_source, login, login_canonical, display_name, locale, timezone, avatar_url, created_at, updated_at, deleted_at, merged_into, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	ON DUPLICATE KEY UPDATE
 		status=VALUES(status),
+	    status_source=VALUES(status_source),
+	    status_expires_at=VALUES(status_expires_at),
+	    status_restore=VALUES(status_restore),
+	    status_restore_source=VALUES(status_restore_source),
 	    login=VALUES(login),
-	    email=VALUES(email),
-	    telegram=VALUES(telegram),
+	    login_canonical=VALUES(login_canonical),
+	    display_name=VALUES(display_name),
+	    locale=VALUES(locale),
+	    timezone=VALUES(timezone),
+	    avatar_url=VALUES(avatar_url),
 	    updated_at=VALUES(updated_at),
-	    deleted_at=VALUES(deleted_at)
+	    deleted_at=VALUES(deleted_at),
+	    merged_into=VALUES(merged_into),
+	    version=VALUES(version)
 	`,
 		user.UserID,
+		user.TenantID,
 		user.Status,
+		user.StatusSource,
+		statusExpiresAt,
+		statusRestore,
+		statusRestoreSource,
 		user.Login,
-		toSQLNull(user.Email),
-		toSQLNull(user.Telegram),
+		model.CanonicalLogin(user.Login),
+		toSQLNull(user.Profile.DisplayName),
+		toSQLNull(user.Profile.Locale),
+		toSQLNull(user.Profile.Timezone),
+		toSQLNull(user.Profile.AvatarURL),
 		user.CreatedAt,
 		user.UpdatedAt,
 		toSQLNull(user.DeletedAt),
+		toSQLNull(user.MergedInto),
+		user.Version,
 	)
-	return errors.WithStack(err)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = u.storeContacts(user.UserID, user.TenantID, user.Contacts)
+	if err != nil {
+		return err
+	}
+	err = u.storeContactVerifications(user.UserID, user.ContactVerifications)
+	if err != nil {
+		return err
+	}
+	err = u.storeAttributes(user.UserID, user.Attributes)
+	if err != nil {
+		return err
+	}
+	err = u.storeLabels(user.UserID, user.Labels)
+	if err != nil {
+		return err
+	}
+	err = u.storeNotificationPreferences(user.UserID, user.NotificationPreferences)
+	if err != nil {
+		return err
+	}
+	err = u.storeRoles(user.UserID, user.Roles)
+	if err != nil {
+		return err
+	}
+	err = u.storeCredentials(user.UserID, user.Credentials)
+	if err != nil {
+		return err
+	}
+	err = u.storeAPITokens(user.UserID, user.APITokens)
+	if err != nil {
+		return err
+	}
+	err = u.storeTOTP(user.UserID, user.TOTP)
+	if err != nil {
+		return err
+	}
+	err = u.storeIdentities(user.UserID, user.TenantID, user.Identities)
+	if err != nil {
+		return err
+	}
+	return u.storeAudit(old, user)
 }
 
 func (u *userRepository) Find(spec model.FindSpec) (*model.User, error) {
 	user := struct {
-		UserID    uuid.UUID           `db:"user_id"`
-		Status    int                 `db:"status"`
-		Login     string              `db:"login"`
-		Email     sql.Null[string]    `db:"email"`
-		Telegram  sql.Null[string]    `db:"telegram"`
-		CreatedAt time.Time           `db:"created_at"`
-		UpdatedAt time.Time           `db:"updated_at"`
-		DeletedAt sql.Null[time.Time] `db:"deleted_at"`
+		UserID              uuid.UUID           `db:"user_id"`
+		TenantID            string              `db:"tenant_id"`
+		Status              int                 `db:"status"`
+		StatusSource        int                 `db:"status_source"`
+		StatusExpiresAt     sql.Null[time.Time] `db:"status_expires_at"`
+		StatusRestore       sql.Null[int]       `db:"status_restore"`
+		StatusRestoreSource sql.Null[int]       `db:"status_restore_source"`
+		Login               string              `db:"login"`
+		DisplayName         sql.Null[string]    `db:"display_name"`
+		Locale              sql.Null[string]    `db:"locale"`
+		Timezone            sql.Null[string]    `db:"timezone"`
+		AvatarURL           sql.Null[string]    `db:"avatar_url"`
+		CreatedAt           time.Time           `db:"created_at"`
+		UpdatedAt           time.Time           `db:"updated_at"`
+		DeletedAt           sql.Null[time.Time] `db:"deleted_at"`
+		MergedInto          sql.Null[uuid.UUID] `db:"merged_into"`
+		Version             int64               `db:"version"`
 	}{}
 	query, args := u.buildSpecArgs(spec)
 
 	err := u.client.GetContext(
 		u.ctx,
 		&user,
-		`SELECT user_id, status, login, email, telegram, created_at, updated_at, deleted_at FROM user WHERE `+query,
+		`SELECT user_id, tenant_id, status, status_source, status_expires_at, status_restore, status_restore_source, login, display_name, locale, timezone, avatar_url, created_at, updated_at, deleted_at, merged_into, version FROM user WHERE `+query,
 		args...,
 	)
 	if err != nil {
@@ -79,40 +165,344 @@ func (u *userRepository) Find(spec model.FindSpec) (*model.User, error) {
 		return nil, errors.WithStack(err)
 	}
 
+	contacts, err := u.findContacts(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	verifications, err := u.findContactVerifications(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := u.findAttributes(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := u.findLabels(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	notificationPreferences, err := u.findNotificationPreferences(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := u.findRoles(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	credentials, err := u.findCredentials(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	apiTokens, err := u.findAPITokens(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	totp, err := u.findTOTP(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	identities, err := u.findIdentities(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var statusExpiration *model.StatusExpiration
+	if user.StatusExpiresAt.Valid {
+		statusExpiration = &model.StatusExpiration{
+			ExpiresAt:           user.StatusExpiresAt.V,
+			RestoreStatus:       model.UserStatus(user.StatusRestore.V),
+			RestoreStatusSource: model.StatusSource(user.StatusRestoreSource.V),
+		}
+	}
+
 	return &model.User{
-		UserID:    user.UserID,
-		Status:    model.UserStatus(user.Status),
-		Login:     user.Login,
-		Email:     fromSQLNull(user.Email),
-		Telegram:  fromSQLNull(user.Telegram),
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-		DeletedAt: fromSQLNull(user.DeletedAt),
+		UserID:           user.UserID,
+		TenantID:         user.TenantID,
+		Status:           model.UserStatus(user.Status),
+		StatusSource:     model.StatusSource(user.StatusSource),
+		StatusExpiration: statusExpiration,
+		Login:            user.Login,
+		Profile: model.Profile{
+			DisplayName: fromSQLNull(user.DisplayName),
+			Locale:      fromSQLNull(user.Locale),
+			Timezone:    fromSQLNull(user.Timezone),
+			AvatarURL:   fromSQLNull(user.AvatarURL),
+		},
+		Contacts:                contacts,
+		Attributes:              attributes,
+		Labels:                  labels,
+		NotificationPreferences: notificationPreferences,
+		Roles:                   roles,
+		ContactVerifications:    verifications,
+		Credentials:             credentials,
+		APITokens:               apiTokens,
+		TOTP:                    totp,
+		Identities:              identities,
+		CreatedAt:               user.CreatedAt,
+		UpdatedAt:               user.UpdatedAt,
+		DeletedAt:               fromSQLNull(user.DeletedAt),
+		MergedInto:              fromSQLNull(user.MergedInto),
+		Version:                 user.Version,
 	}, nil
 }
 
 func (u *userRepository) HardDelete(userID uuid.UUID) error {
-	_, err := u.client.ExecContext(u.ctx, `DELETE FROM user WHERE user_id = ?`, userID)
+	for _, table := range []string{"user_identity", "user_totp_recovery_code", "user_totp", "user_api_token_scope", "user_api_token", "user_credentials", "user_role", "user_notification_preference", "user_label", "user_attribute", "user_contact_verification", "user_contact", "user"} {
+		_, err := u.client.ExecContext(u.ctx, `DELETE FROM `+table+` WHERE user_id = ?`, userID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// storeContacts keeps tenant of user with contacts as contacts are unique within tenant
+func (u *userRepository) storeContacts(userID uuid.UUID, tenantID string, contacts []model.Contact) error {
+	_, err := u.client.ExecContext(u.ctx, `DELETE FROM user_contact WHERE user_id = ?`, userID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(contacts) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(contacts))
+	args := make([]interface{}, 0, len(contacts)*7)
+	for _, contact := range contacts {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, userID, tenantID, contact.Type, contact.Value, contact.Canonical, contact.Primary, toSQLNull(contact.VerifiedAt))
+	}
+	_, err = u.client.ExecContext(u.ctx,
+		`INSERT INTO user_contact (user_id, tenant_id, type, value, canonical_value, is_primary, verified_at) VALUES `+strings.Join(placeholders, ", "),
+		args...,
+	)
 	return errors.WithStack(err)
 }
 
+func (u *userRepository) storeContactVerifications(userID uuid.UUID, verifications []model.ContactVerification) error {
+	_, err := u.client.ExecContext(u.ctx, `DELETE FROM user_contact_verification WHERE user_id = ?`, userID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(verifications) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(verifications))
+	args := make([]interface{}, 0, len(verifications)*6)
+	for _, verification := range verifications {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?)")
+		args = append(args, userID, verification.Type, verification.Value, verification.CodeHash, verification.ExpiresAt, verification.FailedAttempts)
+	}
+	_, err = u.client.ExecContext(u.ctx,
+		`INSERT INTO user_contact_verification (user_id, type, value, code_hash, expires_at, failed_attempts) VALUES `+strings.Join(placeholders, ", "),
+		args...,
+	)
+	return errors.WithStack(err)
+}
+
+func (u *userRepository) findContacts(userID uuid.UUID) ([]model.Contact, error) {
+	var contacts []struct {
+		Type       int                 `db:"type"`
+		Value      string              `db:"value"`
+		Canonical  string              `db:"canonical_value"`
+		Primary    bool                `db:"is_primary"`
+		VerifiedAt sql.Null[time.Time] `db:"verified_at"`
+	}
+	err := u.client.SelectContext(
+		u.ctx,
+		&contacts,
+		`SELECT type, value, canonical_value, is_primary, verified_at FROM user_contact WHERE user_id = ? ORDER BY type, is_primary DESC, value`,
+		userID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]model.Contact, 0, len(contacts))
+	for _, contact := range contacts {
+		result = append(result, model.Contact{
+			Type:       model.ContactType(contact.Type),
+			Value:      contact.Value,
+			Canonical:  contact.Canonical,
+			Primary:    contact.Primary,
+			VerifiedAt: fromSQLNull(contact.VerifiedAt),
+		})
+	}
+	return result, nil
+}
+
+func (u *userRepository) findContactVerifications(userID uuid.UUID) ([]model.ContactVerification, error) {
+	var verifications []struct {
+		Type           int       `db:"type"`
+		Value          string    `db:"value"`
+		CodeHash       string    `db:"code_hash"`
+		ExpiresAt      time.Time `db:"expires_at"`
+		FailedAttempts int       `db:"failed_attempts"`
+	}
+	err := u.client.SelectContext(
+		u.ctx,
+		&verifications,
+		`SELECT type, value, code_hash, expires_at, failed_attempts FROM user_contact_verification WHERE user_id = ?`,
+		userID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]model.ContactVerification, 0, len(verifications))
+	for _, verification := range verifications {
+		result = append(result, model.ContactVerification{
+			Type:           model.ContactType(verification.Type),
+			Value:          verification.Value,
+			CodeHash:       verification.CodeHash,
+			ExpiresAt:      verification.ExpiresAt,
+			FailedAttempts: verification.FailedAttempts,
+		})
+	}
+	return result, nil
+}
+
+func (u *userRepository) storeAttributes(userID uuid.UUID, attributes []model.Attribute) error {
+	_, err := u.client.ExecContext(u.ctx, `DELETE FROM user_attribute WHERE user_id = ?`, userID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(attributes))
+	args := make([]interface{}, 0, len(attributes)*4)
+	for _, attribute := range attributes {
+		placeholders = append(placeholders, "(?, ?, ?, ?)")
+		args = append(args, userID, attribute.Key, attribute.Type, attribute.Value)
+	}
+	_, err = u.client.ExecContext(u.ctx,
+		`INSERT INTO user_attribute (user_id, name, type, value) VALUES `+strings.Join(placeholders, ", "),
+		args...,
+	)
+	return errors.WithStack(err)
+}
+
+func (u *userRepository) storeLabels(userID uuid.UUID, labels []string) error {
+	_, err := u.client.ExecContext(u.ctx, `DELETE FROM user_label WHERE user_id = ?`, userID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(labels))
+	args := make([]interface{}, 0, len(labels)*2)
+	for _, label := range labels {
+		placeholders = append(placeholders, "(?, ?)")
+		args = append(args, userID, label)
+	}
+	_, err = u.client.ExecContext(u.ctx,
+		`INSERT INTO user_label (user_id, label) VALUES `+strings.Join(placeholders, ", "),
+		args...,
+	)
+	return errors.WithStack(err)
+}
+
+func (u *userRepository) findAttributes(userID uuid.UUID) ([]model.Attribute, error) {
+	var attributes []struct {
+		Key   string `db:"name"`
+		Type  int    `db:"type"`
+		Value string `db:"value"`
+	}
+	err := u.client.SelectContext(
+		u.ctx,
+		&attributes,
+		`SELECT name, type, value FROM user_attribute WHERE user_id = ? ORDER BY name`,
+		userID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]model.Attribute, 0, len(attributes))
+	for _, attribute := range attributes {
+		result = append(result, model.Attribute{
+			Key:   attribute.Key,
+			Type:  model.AttributeType(attribute.Type),
+			Value: attribute.Value,
+		})
+	}
+	return result, nil
+}
+
+func (u *userRepository) findLabels(userID uuid.UUID) ([]string, error) {
+	var labels []string
+	err := u.client.SelectContext(
+		u.ctx,
+		&labels,
+		`SELECT label FROM user_label WHERE user_id = ? ORDER BY label`,
+		userID,
+	)
+	return labels, errors.WithStack(err)
+}
+
+func (u *userRepository) storeRoles(userID uuid.UUID, roles []string) error {
+	_, err := u.client.ExecContext(u.ctx, `DELETE FROM user_role WHERE user_id = ?`, userID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(roles))
+	args := make([]interface{}, 0, len(roles)*2)
+	for _, role := range roles {
+		placeholders = append(placeholders, "(?, ?)")
+		args = append(args, userID, role)
+	}
+	_, err = u.client.ExecContext(u.ctx,
+		`INSERT INTO user_role (user_id, role) VALUES `+strings.Join(placeholders, ", "),
+		args...,
+	)
+	return errors.WithStack(err)
+}
+
+func (u *userRepository) findRoles(userID uuid.UUID) ([]string, error) {
+	var roles []string
+	err := u.client.SelectContext(
+		u.ctx,
+		&roles,
+		`SELECT role FROM user_role WHERE user_id = ? ORDER BY role`,
+		userID,
+	)
+	return roles, errors.WithStack(err)
+}
+
 func (u *userRepository) buildSpecArgs(spec model.FindSpec) (query string, args []interface{}) {
 	var parts []string
+	if spec.TenantID != nil {
+		parts = append(parts, "tenant_id = ?")
+		args = append(args, *spec.TenantID)
+	}
 	if spec.UserID != nil {
 		parts = append(parts, "user_id = ?")
 		args = append(args, *spec.UserID)
 	}
 	if spec.Login != nil {
-		parts = append(parts, "login = ?")
+		parts = append(parts, "login_canonical = ?")
 		args = append(args, *spec.Login)
 	}
-	if spec.Email != nil {
-		parts = append(parts, "email = ?")
-		args = append(args, *spec.Email)
+	if spec.Contact != nil {
+		parts = append(parts, "user_id IN (SELECT user_id FROM user_contact WHERE type = ? AND canonical_value = ?)")
+		args = append(args, spec.Contact.Type, spec.Contact.Value)
+	}
+	if spec.APITokenHash != nil {
+		parts = append(parts, "user_id IN (SELECT user_id FROM user_api_token WHERE token_hash = ?)")
+		args = append(args, *spec.APITokenHash)
 	}
-	if spec.Telegram != nil {
-		parts = append(parts, "telegram = ?")
-		args = append(args, *spec.Telegram)
+	if spec.Identity != nil {
+		parts = append(parts, "user_id IN (SELECT user_id FROM user_identity WHERE issuer = ? AND subject = ?)")
+		args = append(args, spec.Identity.Issuer, spec.Identity.Subject)
 	}
 	return strings.Join(parts, " AND "), args
 }
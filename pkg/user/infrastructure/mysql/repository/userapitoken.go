@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"userservice/pkg/user/domain/model"
+)
+
+func (u *userRepository) storeAPITokens(userID uuid.UUID, tokens []model.APIToken) error {
+	for _, table := range []string{"user_api_token_scope", "user_api_token"} {
+		_, err := u.client.ExecContext(u.ctx, `DELETE FROM `+table+` WHERE user_id = ?`, userID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(tokens))
+	args := make([]interface{}, 0, len(tokens)*7)
+	var (
+		scopePlaceholders []string
+		scopeArgs         []interface{}
+	)
+	for _, token := range tokens {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, token.TokenID, userID, token.Name, token.TokenHash, toSQLNull(token.ExpiresAt), toSQLNull(token.LastUsedAt), token.CreatedAt)
+		for _, scope := range token.Scopes {
+			scopePlaceholders = append(scopePlaceholders, "(?, ?, ?)")
+			scopeArgs = append(scopeArgs, token.TokenID, userID, scope)
+		}
+	}
+	_, err := u.client.ExecContext(u.ctx,
+		`INSERT INTO user_api_token (token_id, user_id, name, token_hash, expires_at, last_used_at, created_at) VALUES `+strings.Join(placeholders, ", "),
+		args...,
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(scopePlaceholders) == 0 {
+		return nil
+	}
+
+	_, err = u.client.ExecContext(u.ctx,
+		`INSERT INTO user_api_token_scope (token_id, user_id, scope) VALUES `+strings.Join(scopePlaceholders, ", "),
+		scopeArgs...,
+	)
+	return errors.WithStack(err)
+}
+
+// StoreAPITokenUsage never moves last usage time back as usages are recorded without lock of user
+func (u *userRepository) StoreAPITokenUsage(tokenID uuid.UUID, usedAt time.Time) error {
+	_, err := u.client.ExecContext(u.ctx,
+		`UPDATE user_api_token SET last_used_at = ? WHERE token_id = ? AND (last_used_at IS NULL OR last_used_at < ?)`,
+		usedAt,
+		tokenID,
+		usedAt,
+	)
+	return errors.WithStack(err)
+}
+
+func (u *userRepository) findAPITokens(userID uuid.UUID) ([]model.APIToken, error) {
+	var tokens []struct {
+		TokenID    uuid.UUID           `db:"token_id"`
+		Name       string              `db:"name"`
+		TokenHash  string              `db:"token_hash"`
+		ExpiresAt  sql.Null[time.Time] `db:"expires_at"`
+		LastUsedAt sql.Null[time.Time] `db:"last_used_at"`
+		CreatedAt  time.Time           `db:"created_at"`
+	}
+	err := u.client.SelectContext(
+		u.ctx,
+		&tokens,
+		`SELECT token_id, name, token_hash, expires_at, last_used_at, created_at FROM user_api_token WHERE user_id = ? ORDER BY token_id`,
+		userID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	var scopes []struct {
+		TokenID uuid.UUID `db:"token_id"`
+		Scope   string    `db:"scope"`
+	}
+	err = u.client.SelectContext(
+		u.ctx,
+		&scopes,
+		`SELECT token_id, scope FROM user_api_token_scope WHERE user_id = ? ORDER BY scope`,
+		userID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	tokenScopes := make(map[uuid.UUID][]string, len(tokens))
+	for _, scope := range scopes {
+		tokenScopes[scope.TokenID] = append(tokenScopes[scope.TokenID], scope.Scope)
+	}
+
+	result := make([]model.APIToken, 0, len(tokens))
+	for _, token := range tokens {
+		result = append(result, model.APIToken{
+			TokenID:    token.TokenID,
+			Name:       token.Name,
+			Scopes:     tokenScopes[token.TokenID],
+			TokenHash:  token.TokenHash,
+			ExpiresAt:  fromSQLNull(token.ExpiresAt),
+			LastUsedAt: fromSQLNull(token.LastUsedAt),
+			CreatedAt:  token.CreatedAt,
+		})
+	}
+	return result, nil
+}
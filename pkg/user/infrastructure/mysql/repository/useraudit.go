@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"userservice/pkg/user/application/service"
+	"userservice/pkg/user/domain/model"
+)
+
+// storeAudit appends changed fields of user with their previous values to user_audit,
+// nothing is written when audited fields are unchanged
+func (u *userRepository) storeAudit(old *model.User, user model.User) error {
+	newValues, err := auditValues(&user)
+	if err != nil {
+		return err
+	}
+	oldValues, err := auditValues(old)
+	if err != nil {
+		return err
+	}
+
+	changed := make(map[string]json.RawMessage, len(newValues))
+	var previous map[string]json.RawMessage
+	if old != nil {
+		previous = make(map[string]json.RawMessage, len(oldValues))
+	}
+	for field, value := range newValues {
+		if old != nil && bytes.Equal(oldValues[field], value) {
+			continue
+		}
+		changed[field] = value
+		if old != nil {
+			previous[field] = oldValues[field]
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	newValue, err := json.Marshal(changed)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var oldValue []byte
+	if previous != nil {
+		oldValue, err = json.Marshal(previous)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	actor := service.ActorFromContext(u.ctx)
+	_, err = u.client.ExecContext(u.ctx,
+		`
+	INSERT INTO user_audit (user_id, tenant_id, actor, source, correlation_id, old_value, new_value, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		user.UserID,
+		user.TenantID,
+		actor.ID,
+		actor.Source,
+		actor.CorrelationID,
+		oldValue,
+		newValue,
+		time.Now(),
+	)
+	return errors.WithStack(err)
+}
+
+// auditValues returns audited fields of user in JSON, contact verifications and credentials are not audited as they hold hashes,
+// only time of password change is, api tokens are audited without hashes and usage times,
+// of totp factor only time of its confirmation is audited
+func auditValues(user *model.User) (map[string]json.RawMessage, error) {
+	if user == nil {
+		return nil, nil
+	}
+	var statusExpiresAt *time.Time
+	if user.StatusExpiration != nil {
+		statusExpiresAt = &user.StatusExpiration.ExpiresAt
+	}
+	var passwordChangedAt *time.Time
+	if user.Credentials != nil {
+		passwordChangedAt = &user.Credentials.PasswordChangedAt
+	}
+	attributes := make(map[string]string, len(user.Attributes))
+	for _, attribute := range user.Attributes {
+		attributes[attribute.Key] = attribute.Value
+	}
+	contacts := make([]auditContact, 0, len(user.Contacts))
+	for _, contact := range user.Contacts {
+		contacts = append(contacts, auditContact{
+			Type:       int(contact.Type),
+			Value:      contact.Value,
+			Primary:    contact.Primary,
+			VerifiedAt: contact.VerifiedAt,
+		})
+	}
+
+	var totpEnrolledAt *time.Time
+	if user.TOTP != nil {
+		totpEnrolledAt = user.TOTP.ConfirmedAt
+	}
+	notificationPreferences := make([]auditNotificationPreference, 0, len(user.NotificationPreferences))
+	for _, preference := range user.NotificationPreferences {
+		notificationPreferences = append(notificationPreferences, auditNotificationPreference{
+			Channel:  int(preference.Channel),
+			Category: preference.Category,
+			Enabled:  preference.Enabled,
+		})
+	}
+	identities := make([]auditIdentity, 0, len(user.Identities))
+	for _, identity := range user.Identities {
+		identities = append(identities, auditIdentity{
+			Issuer:  identity.Issuer,
+			Subject: identity.Subject,
+		})
+	}
+	apiTokens := make([]auditAPIToken, 0, len(user.APITokens))
+	for _, token := range user.APITokens {
+		apiTokens = append(apiTokens, auditAPIToken{
+			TokenID:   token.TokenID.String(),
+			Name:      token.Name,
+			Scopes:    nonNil(token.Scopes),
+			ExpiresAt: token.ExpiresAt,
+		})
+	}
+
+	fields := map[string]any{
+		"status":                   user.Status,
+		"status_source":            user.StatusSource,
+		"status_expires_at":        statusExpiresAt,
+		"login":                    user.Login,
+		"display_name":             user.Profile.DisplayName,
+		"locale":                   user.Profile.Locale,
+		"timezone":                 user.Profile.Timezone,
+		"avatar_url":               user.Profile.AvatarURL,
+		"contacts":                 contacts,
+		"attributes":               attributes,
+		"labels":                   nonNil(user.Labels),
+		"roles":                    nonNil(user.Roles),
+		"notification_preferences": notificationPreferences,
+		"deleted_at":               user.DeletedAt,
+		"merged_into":              user.MergedInto,
+		"password_changed_at":      passwordChangedAt,
+		"api_tokens":               apiTokens,
+		"totp_enrolled_at":         totpEnrolledAt,
+		"identities":               identities,
+	}
+	result := make(map[string]json.RawMessage, len(fields))
+	for field, value := range fields {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		result[field] = data
+	}
+	return result, nil
+}
+
+type auditContact struct {
+	Type       int        `json:"type"`
+	Value      string     `json:"value"`
+	Primary    bool       `json:"primary"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+}
+
+type auditNotificationPreference struct {
+	Channel  int    `json:"channel"`
+	Category string `json:"category"`
+	Enabled  bool   `json:"enabled"`
+}
+
+type auditIdentity struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+}
+
+type auditAPIToken struct {
+	TokenID   string     `json:"token_id"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// nonNil keeps empty slices from being written as null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
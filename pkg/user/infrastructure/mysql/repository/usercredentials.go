@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"userservice/pkg/user/domain/model"
+)
+
+func (u *userRepository) storeCredentials(userID uuid.UUID, credentials *model.Credentials) error {
+	if credentials == nil {
+		_, err := u.client.ExecContext(u.ctx, `DELETE FROM user_credentials WHERE user_id = ?`, userID)
+		return errors.WithStack(err)
+	}
+
+	_, err := u.client.ExecContext(u.ctx,
+		`
+	INSERT INTO user_credentials (user_id, password_hash, failed_attempts, locked_until, password_changed_at) VALUES (?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		password_hash=VALUES(password_hash),
+	    failed_attempts=VALUES(failed_attempts),
+	    locked_until=VALUES(locked_until),
+	    password_changed_at=VALUES(password_changed_at)
+	`,
+		userID,
+		credentials.PasswordHash,
+		credentials.FailedAttempts,
+		toSQLNull(credentials.LockedUntil),
+		credentials.PasswordChangedAt,
+	)
+	return errors.WithStack(err)
+}
+
+func (u *userRepository) findCredentials(userID uuid.UUID) (*model.Credentials, error) {
+	credentials := struct {
+		PasswordHash      string              `db:"password_hash"`
+		FailedAttempts    int                 `db:"failed_attempts"`
+		LockedUntil       sql.Null[time.Time] `db:"locked_until"`
+		PasswordChangedAt time.Time           `db:"password_changed_at"`
+	}{}
+	err := u.client.GetContext(
+		u.ctx,
+		&credentials,
+		`SELECT password_hash, failed_attempts, locked_until, password_changed_at FROM user_credentials WHERE user_id = ?`,
+		userID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	return &model.Credentials{
+		PasswordHash:      credentials.PasswordHash,
+		FailedAttempts:    credentials.FailedAttempts,
+		LockedUntil:       fromSQLNull(credentials.LockedUntil),
+		PasswordChangedAt: credentials.PasswordChangedAt,
+	}, nil
+}
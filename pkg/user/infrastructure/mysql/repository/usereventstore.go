@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"userservice/pkg/user/domain/model"
+)
+
+// userSnapshotInterval is the number of events after which snapshot of user state is taken
+const userSnapshotInterval = 20
+
+// NewEventSourcedUserRepository returns repository storing user as ordered stream of changes in user_event
+// with snapshots in user_snapshot, user tables are kept as projection of the stream and are used to look users up.
+// Users stored before stream was introduced are read from projection until their first change
+func NewEventSourcedUserRepository(ctx context.Context, client mysql.ClientContext) model.UserRepository {
+	return &eventSourcedUserRepository{
+		ctx:    ctx,
+		client: client,
+		projection: &userRepository{
+			ctx:    ctx,
+			client: client,
+		},
+	}
+}
+
+type eventSourcedUserRepository struct {
+	ctx        context.Context
+	client     mysql.ClientContext
+	projection *userRepository
+}
+
+// userDocument is user state as JSON object of userState fields, event holds only fields changed by it
+type userDocument map[string]json.RawMessage
+
+func (u *eventSourcedUserRepository) NextID() (uuid.UUID, error) {
+	return u.projection.NextID()
+}
+
+func (u *eventSourcedUserRepository) Store(user model.User) error {
+	document, version, err := u.loadDocument(user.UserID)
+	if err != nil {
+		return err
+	}
+
+	// stream of new user as well as of user stored before event sourcing starts with full state
+	newDocument, err := toUserDocument(user)
+	if err != nil {
+		return err
+	}
+	changes := diffUserDocuments(document, newDocument)
+	// changes of secrets and counters of attempts are stored only in projection
+	if len(changes) == 0 {
+		return u.projection.Store(user)
+	}
+
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	version++
+	currentTime := time.Now()
+	_, err = u.client.ExecContext(u.ctx,
+		`INSERT INTO user_event (user_id, version, payload, created_at) VALUES (?, ?, ?, ?)`,
+		user.UserID,
+		version,
+		payload,
+		currentTime,
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if version%userSnapshotInterval == 0 {
+		state, err := json.Marshal(newDocument)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		_, err = u.client.ExecContext(u.ctx,
+			`
+	INSERT INTO user_snapshot (user_id, version, state, created_at) VALUES (?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		version=VALUES(version),
+	    state=VALUES(state),
+	    created_at=VALUES(created_at)
+	`,
+			user.UserID,
+			version,
+			state,
+			currentTime,
+		)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return u.projection.Store(user)
+}
+
+func (u *eventSourcedUserRepository) Find(spec model.FindSpec) (*model.User, error) {
+	var userID uuid.UUID
+	// projection is queried to check tenant of user even when user is looked up by identifier
+	if spec.UserID != nil && spec.TenantID == nil {
+		userID = *spec.UserID
+	} else {
+		query, args := u.projection.buildSpecArgs(spec)
+		err := u.client.GetContext(u.ctx, &userID, `SELECT user_id FROM user WHERE `+query, args...)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errors.WithStack(model.ErrUserNotFound)
+			}
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	document, _, err := u.loadDocument(userID)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return u.projection.Find(model.FindSpec{UserID: &userID})
+	}
+
+	user, err := fromUserDocument(document)
+	if err != nil {
+		return nil, err
+	}
+	err = u.withSecrets(&user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// withSecrets fills parts of user holding secrets and counters of attempts, they are read from projection rows
+func (u *eventSourcedUserRepository) withSecrets(user *model.User) error {
+	var err error
+	user.Credentials, err = u.projection.findCredentials(user.UserID)
+	if err != nil {
+		return err
+	}
+	user.TOTP, err = u.projection.findTOTP(user.UserID)
+	if err != nil {
+		return err
+	}
+	user.APITokens, err = u.projection.findAPITokens(user.UserID)
+	if err != nil {
+		return err
+	}
+	user.ContactVerifications, err = u.projection.findContactVerifications(user.UserID)
+	return err
+}
+
+// HardDelete removes stream of user along with projection
+func (u *eventSourcedUserRepository) HardDelete(userID uuid.UUID) error {
+	for _, table := range []string{"user_snapshot", "user_event"} {
+		_, err := u.client.ExecContext(u.ctx, `DELETE FROM `+table+` WHERE user_id = ?`, userID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return u.projection.HardDelete(userID)
+}
+
+// StoreAPITokenUsage is kept only in projection, usage of token is not a change of user
+func (u *eventSourcedUserRepository) StoreAPITokenUsage(tokenID uuid.UUID, usedAt time.Time) error {
+	return u.projection.StoreAPITokenUsage(tokenID, usedAt)
+}
+
+// loadDocument replays events of user over the latest snapshot, nil document is returned for user without stream
+func (u *eventSourcedUserRepository) loadDocument(userID uuid.UUID) (userDocument, int64, error) {
+	snapshot := struct {
+		Version int64  `db:"version"`
+		State   []byte `db:"state"`
+	}{}
+	err := u.client.GetContext(u.ctx, &snapshot, `SELECT version, state FROM user_snapshot WHERE user_id = ?`, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, errors.WithStack(err)
+	}
+
+	var document userDocument
+	if snapshot.State != nil {
+		err = json.Unmarshal(snapshot.State, &document)
+		if err != nil {
+			return nil, 0, errors.WithStack(err)
+		}
+	}
+
+	var events []struct {
+		Version int64  `db:"version"`
+		Payload []byte `db:"payload"`
+	}
+	err = u.client.SelectContext(u.ctx, &events,
+		`SELECT version, payload FROM user_event WHERE user_id = ? AND version > ? ORDER BY version`,
+		userID,
+		snapshot.Version,
+	)
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+
+	version := snapshot.Version
+	for _, event := range events {
+		var changes userDocument
+		err = json.Unmarshal(event.Payload, &changes)
+		if err != nil {
+			return nil, 0, errors.WithStack(err)
+		}
+		document = applyUserDocumentChanges(document, changes)
+		version = event.Version
+	}
+	return document, version, nil
+}
+
+// diffUserDocuments returns fields of next document that differ from document, all fields differ from nil document
+func diffUserDocuments(document, next userDocument) userDocument {
+	changes := make(userDocument, len(next))
+	for field, value := range next {
+		if !bytes.Equal(document[field], value) {
+			changes[field] = value
+		}
+	}
+	return changes
+}
+
+// applyUserDocumentChanges replays event over document, stream starts with event holding full state
+func applyUserDocumentChanges(document, changes userDocument) userDocument {
+	if document == nil {
+		document = make(userDocument, len(changes))
+	}
+	for field, value := range changes {
+		document[field] = value
+	}
+	return document
+}
+
+func fromUserDocument(document userDocument) (model.User, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return model.User{}, errors.WithStack(err)
+	}
+	var state userState
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return model.User{}, errors.WithStack(err)
+	}
+	return fromUserState(state), nil
+}
+
+func toUserDocument(user model.User) (userDocument, error) {
+	data, err := json.Marshal(toUserState(user))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var document userDocument
+	err = json.Unmarshal(data, &document)
+	return document, errors.WithStack(err)
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"userservice/pkg/user/domain/model"
+)
+
+func TestDiffUserDocuments(t *testing.T) {
+	user := testUser()
+	document, err := toUserDocument(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		document userDocument
+		change   func(user *model.User)
+		fields   []string
+	}{
+		{name: "first event", change: func(*model.User) {}, fields: sortedFields(document)},
+		{name: "unchanged user", document: document, change: func(*model.User) {}},
+		{
+			name:     "changed login",
+			document: document,
+			change: func(user *model.User) {
+				user.Login = "other"
+				user.UpdatedAt = user.UpdatedAt.Add(time.Minute)
+				user.Version++
+			},
+			fields: []string{"login", "updated_at", "version"},
+		},
+		{
+			name:     "changed secrets and counters",
+			document: document,
+			change: func(user *model.User) {
+				user.Credentials.PasswordHash = "other hash"
+				user.Credentials.FailedAttempts = 3
+				user.TOTP.LastUsedStep = 100
+				user.TOTP.FailedAttempts = 1
+				user.APITokens[0].TokenHash = "other hash"
+				lastUsedAt := user.UpdatedAt.Add(time.Hour)
+				user.APITokens[0].LastUsedAt = &lastUsedAt
+				user.ContactVerifications[0].CodeHash = "other hash"
+				user.ContactVerifications[0].FailedAttempts = 2
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := testUser()
+			tt.change(&next)
+			nextDocument, err := toUserDocument(next)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fields := sortedFields(diffUserDocuments(tt.document, nextDocument))
+			if len(fields) != len(tt.fields) || (len(fields) > 0 && !reflect.DeepEqual(fields, tt.fields)) {
+				t.Errorf("diffUserDocuments() changed %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestReplayUserDocument(t *testing.T) {
+	first := testUser()
+
+	second := testUser()
+	second.Login = "other"
+	second.Labels = []string{"vip"}
+	second.Version++
+
+	third := testUser()
+	third.Login = "other"
+	third.Labels = []string{"vip"}
+	third.Status = model.Blocked
+	third.Contacts = nil
+	third.Credentials.FailedAttempts = 5
+	third.Version += 2
+
+	var document, replayed userDocument
+	for _, user := range []model.User{first, second, third} {
+		next, err := toUserDocument(user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// events hold only changes, so replayed document has to match the last state
+		replayed = applyUserDocumentChanges(replayed, diffUserDocuments(document, next))
+		document = next
+	}
+
+	user, err := fromUserDocument(replayed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.UserID != third.UserID || user.Login != third.Login || user.Status != third.Status || user.Version != third.Version {
+		t.Errorf("fromUserDocument() = %+v, want state of %+v", user, third)
+	}
+	if user.TenantID != third.TenantID {
+		t.Errorf("fromUserDocument() tenant = %q, want %q", user.TenantID, third.TenantID)
+	}
+	if len(user.Contacts) != 0 {
+		t.Errorf("fromUserDocument() contacts = %+v, want none", user.Contacts)
+	}
+	if !reflect.DeepEqual(user.Labels, third.Labels) || !reflect.DeepEqual(user.Roles, third.Roles) {
+		t.Errorf("fromUserDocument() labels = %v, roles = %v, want %v, %v", user.Labels, user.Roles, third.Labels, third.Roles)
+	}
+	if !user.UpdatedAt.Equal(third.UpdatedAt) {
+		t.Errorf("fromUserDocument() updated at = %v, want %v", user.UpdatedAt, third.UpdatedAt)
+	}
+	// secrets are not stored in events, repository reads them from projection
+	if user.Credentials != nil || user.TOTP != nil || len(user.APITokens) != 0 || len(user.ContactVerifications) != 0 {
+		t.Errorf("fromUserDocument() returned secrets %+v", user)
+	}
+}
+
+func testUser() model.User {
+	currentTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	expiresAt := currentTime.Add(time.Hour)
+	return model.User{
+		UserID:   uuid.MustParse("5f8d2a9c-1b3e-4c6d-8e7f-9a0b1c2d3e4f"),
+		TenantID: "tenant",
+		Status:   model.Active,
+		Login:    "login",
+		Contacts: []model.Contact{
+			{Type: model.ContactEmail, Value: "User@Example.com", Canonical: "user@example.com", Primary: true, VerifiedAt: &currentTime},
+		},
+		Roles: []string{"admin"},
+		ContactVerifications: []model.ContactVerification{
+			{Type: model.ContactEmail, Value: "other@example.com", CodeHash: "hash", ExpiresAt: expiresAt},
+		},
+		Credentials: &model.Credentials{PasswordHash: "hash", PasswordChangedAt: currentTime},
+		APITokens: []model.APIToken{
+			{TokenID: uuid.MustParse("0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"), Name: "ci", Scopes: []string{"users:read"}, TokenHash: "hash", CreatedAt: currentTime},
+		},
+		TOTP:      &model.TOTPFactor{EncryptedSecret: []byte("secret"), ConfirmedAt: &currentTime},
+		CreatedAt: currentTime,
+		UpdatedAt: currentTime,
+		Version:   1,
+	}
+}
+
+func sortedFields(document userDocument) []string {
+	fields := make([]string, 0, len(document))
+	for field := range document {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
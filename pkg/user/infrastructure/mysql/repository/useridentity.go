@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"userservice/pkg/user/domain/model"
+)
+
+// storeIdentities keeps tenant of user with identities as identities are unique within tenant
+func (u *userRepository) storeIdentities(userID uuid.UUID, tenantID string, identities []model.Identity) error {
+	_, err := u.client.ExecContext(u.ctx, `DELETE FROM user_identity WHERE user_id = ?`, userID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(identities) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(identities))
+	args := make([]interface{}, 0, len(identities)*5)
+	for _, identity := range identities {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?)")
+		args = append(args, userID, tenantID, identity.Issuer, identity.Subject, identity.LinkedAt)
+	}
+	_, err = u.client.ExecContext(u.ctx,
+		`INSERT INTO user_identity (user_id, tenant_id, issuer, subject, linked_at) VALUES `+strings.Join(placeholders, ", "),
+		args...,
+	)
+	return errors.WithStack(err)
+}
+
+func (u *userRepository) findIdentities(userID uuid.UUID) ([]model.Identity, error) {
+	var identities []struct {
+		Issuer   string    `db:"issuer"`
+		Subject  string    `db:"subject"`
+		LinkedAt time.Time `db:"linked_at"`
+	}
+	err := u.client.SelectContext(
+		u.ctx,
+		&identities,
+		`SELECT issuer, subject, linked_at FROM user_identity WHERE user_id = ? ORDER BY linked_at, issuer, subject`,
+		userID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]model.Identity, 0, len(identities))
+	for _, identity := range identities {
+		result = append(result, model.Identity{
+			Issuer:   identity.Issuer,
+			Subject:  identity.Subject,
+			LinkedAt: identity.LinkedAt,
+		})
+	}
+	return result, nil
+}
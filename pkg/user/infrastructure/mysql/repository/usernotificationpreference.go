@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"userservice/pkg/user/domain/model"
+)
+
+func (u *userRepository) storeNotificationPreferences(userID uuid.UUID, preferences []model.NotificationPreference) error {
+	_, err := u.client.ExecContext(u.ctx, `DELETE FROM user_notification_preference WHERE user_id = ?`, userID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(preferences) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(preferences))
+	args := make([]interface{}, 0, len(preferences)*4)
+	for _, preference := range preferences {
+		placeholders = append(placeholders, "(?, ?, ?, ?)")
+		args = append(args, userID, preference.Channel, preference.Category, preference.Enabled)
+	}
+	_, err = u.client.ExecContext(u.ctx,
+		`INSERT INTO user_notification_preference (user_id, channel, category, enabled) VALUES `+strings.Join(placeholders, ", "),
+		args...,
+	)
+	return errors.WithStack(err)
+}
+
+func (u *userRepository) findNotificationPreferences(userID uuid.UUID) ([]model.NotificationPreference, error) {
+	var preferences []struct {
+		Channel  int    `db:"channel"`
+		Category string `db:"category"`
+		Enabled  bool   `db:"enabled"`
+	}
+	// binary collation keeps order of categories the same as domain sorts them in
+	err := u.client.SelectContext(
+		u.ctx,
+		&preferences,
+		`SELECT channel, category, enabled FROM user_notification_preference WHERE user_id = ? ORDER BY channel, category COLLATE utf8mb4_bin`,
+		userID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var result []model.NotificationPreference
+	for _, preference := range preferences {
+		result = append(result, model.NotificationPreference{
+			Channel:  model.ContactType(preference.Channel),
+			Category: preference.Category,
+			Enabled:  preference.Enabled,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"userservice/pkg/user/domain/model"
+)
+
+// userState is the stored form of user aggregate in event stream, field names are kept stable to replay old events.
+// Secrets and counters of attempts are kept only in rows of projection, so stream holds just their history:
+// credentials, totp factor, api tokens and contact verifications are read from rows
+type userState struct {
+	UserID uuid.UUID `json:"user_id"`
+	// TenantID is empty in states stored before tenants were introduced
+	TenantID                string                        `json:"tenant_id,omitempty"`
+	Status                  int                           `json:"status"`
+	StatusSource            int                           `json:"status_source"`
+	StatusExpiration        *statusExpirationState        `json:"status_expiration"`
+	Login                   string                        `json:"login"`
+	DisplayName             *string                       `json:"display_name"`
+	Locale                  *string                       `json:"locale"`
+	Timezone                *string                       `json:"timezone"`
+	AvatarURL               *string                       `json:"avatar_url"`
+	Contacts                []contactState                `json:"contacts"`
+	Attributes              []attributeState              `json:"attributes"`
+	Labels                  []string                      `json:"labels"`
+	NotificationPreferences []notificationPreferenceState `json:"notification_preferences"`
+	Roles                   []string                      `json:"roles"`
+	ContactVerifications    []contactVerificationState    `json:"contact_verifications"`
+	Credentials             *credentialsState             `json:"credentials"`
+	APITokens               []apiTokenState               `json:"api_tokens"`
+	TOTP                    *totpState                    `json:"totp"`
+	Identities              []identityState               `json:"identities"`
+	CreatedAt               time.Time                     `json:"created_at"`
+	UpdatedAt               time.Time                     `json:"updated_at"`
+	DeletedAt               *time.Time                    `json:"deleted_at"`
+	MergedInto              *uuid.UUID                    `json:"merged_into"`
+	Version                 int64                         `json:"version"`
+}
+
+type statusExpirationState struct {
+	ExpiresAt           time.Time `json:"expires_at"`
+	RestoreStatus       int       `json:"restore_status"`
+	RestoreStatusSource int       `json:"restore_status_source"`
+}
+
+type contactState struct {
+	Type       int        `json:"type"`
+	Value      string     `json:"value"`
+	Canonical  string     `json:"canonical"`
+	Primary    bool       `json:"primary"`
+	VerifiedAt *time.Time `json:"verified_at"`
+}
+
+type attributeState struct {
+	Key   string `json:"key"`
+	Type  int    `json:"type"`
+	Value string `json:"value"`
+}
+
+type notificationPreferenceState struct {
+	Channel  int    `json:"channel"`
+	Category string `json:"category"`
+	Enabled  bool   `json:"enabled"`
+}
+
+type contactVerificationState struct {
+	Type      int       `json:"type"`
+	Value     string    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type credentialsState struct {
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+}
+
+type apiTokenState struct {
+	TokenID   uuid.UUID  `json:"token_id"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type totpState struct {
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type identityState struct {
+	Issuer   string    `json:"issuer"`
+	Subject  string    `json:"subject"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+func toUserState(user model.User) userState {
+	state := userState{
+		UserID:               user.UserID,
+		TenantID:             user.TenantID,
+		Status:               int(user.Status),
+		StatusSource:         int(user.StatusSource),
+		Login:                user.Login,
+		DisplayName:          user.Profile.DisplayName,
+		Locale:               user.Profile.Locale,
+		Timezone:             user.Profile.Timezone,
+		AvatarURL:            user.Profile.AvatarURL,
+		Contacts:             make([]contactState, 0, len(user.Contacts)),
+		Attributes:           make([]attributeState, 0, len(user.Attributes)),
+		Labels:               nonNil(user.Labels),
+		Roles:                nonNil(user.Roles),
+		ContactVerifications: make([]contactVerificationState, 0, len(user.ContactVerifications)),
+		CreatedAt:            user.CreatedAt,
+		UpdatedAt:            user.UpdatedAt,
+		DeletedAt:            user.DeletedAt,
+		MergedInto:           user.MergedInto,
+		Version:              user.Version,
+	}
+	if user.StatusExpiration != nil {
+		state.StatusExpiration = &statusExpirationState{
+			ExpiresAt:           user.StatusExpiration.ExpiresAt,
+			RestoreStatus:       int(user.StatusExpiration.RestoreStatus),
+			RestoreStatusSource: int(user.StatusExpiration.RestoreStatusSource),
+		}
+	}
+	if user.Credentials != nil {
+		state.Credentials = &credentialsState{
+			PasswordChangedAt: user.Credentials.PasswordChangedAt,
+		}
+	}
+	if user.TOTP != nil {
+		state.TOTP = &totpState{
+			ConfirmedAt: user.TOTP.ConfirmedAt,
+			CreatedAt:   user.TOTP.CreatedAt,
+		}
+	}
+	for _, contact := range user.Contacts {
+		state.Contacts = append(state.Contacts, contactState{
+			Type:       int(contact.Type),
+			Value:      contact.Value,
+			Canonical:  contact.Canonical,
+			Primary:    contact.Primary,
+			VerifiedAt: contact.VerifiedAt,
+		})
+	}
+	for _, attribute := range user.Attributes {
+		state.Attributes = append(state.Attributes, attributeState{
+			Key:   attribute.Key,
+			Type:  int(attribute.Type),
+			Value: attribute.Value,
+		})
+	}
+	for _, verification := range user.ContactVerifications {
+		state.ContactVerifications = append(state.ContactVerifications, contactVerificationState{
+			Type:      int(verification.Type),
+			Value:     verification.Value,
+			ExpiresAt: verification.ExpiresAt,
+		})
+	}
+	for _, preference := range user.NotificationPreferences {
+		state.NotificationPreferences = append(state.NotificationPreferences, notificationPreferenceState{
+			Channel:  int(preference.Channel),
+			Category: preference.Category,
+			Enabled:  preference.Enabled,
+		})
+	}
+	for _, identity := range user.Identities {
+		state.Identities = append(state.Identities, identityState{
+			Issuer:   identity.Issuer,
+			Subject:  identity.Subject,
+			LinkedAt: identity.LinkedAt,
+		})
+	}
+	for _, token := range user.APITokens {
+		state.APITokens = append(state.APITokens, apiTokenState{
+			TokenID:   token.TokenID,
+			Name:      token.Name,
+			Scopes:    token.Scopes,
+			ExpiresAt: token.ExpiresAt,
+			CreatedAt: token.CreatedAt,
+		})
+	}
+	return state
+}
+
+// fromUserState builds user without credentials, totp factor, api tokens and contact verifications,
+// they are read from rows of projection
+func fromUserState(state userState) model.User {
+	user := model.User{
+		UserID:       state.UserID,
+		TenantID:     state.TenantID,
+		Status:       model.UserStatus(state.Status),
+		StatusSource: model.StatusSource(state.StatusSource),
+		Login:        state.Login,
+		Profile: model.Profile{
+			DisplayName: state.DisplayName,
+			Locale:      state.Locale,
+			Timezone:    state.Timezone,
+			AvatarURL:   state.AvatarURL,
+		},
+		Contacts:   make([]model.Contact, 0, len(state.Contacts)),
+		Attributes: make([]model.Attribute, 0, len(state.Attributes)),
+		Labels:     state.Labels,
+		Roles:      state.Roles,
+		CreatedAt:  state.CreatedAt,
+		UpdatedAt:  state.UpdatedAt,
+		DeletedAt:  state.DeletedAt,
+		MergedInto: state.MergedInto,
+		Version:    state.Version,
+	}
+	if state.StatusExpiration != nil {
+		user.StatusExpiration = &model.StatusExpiration{
+			ExpiresAt:           state.StatusExpiration.ExpiresAt,
+			RestoreStatus:       model.UserStatus(state.StatusExpiration.RestoreStatus),
+			RestoreStatusSource: model.StatusSource(state.StatusExpiration.RestoreStatusSource),
+		}
+	}
+	for _, contact := range state.Contacts {
+		if contact.Canonical == "" {
+			// contacts stored before canonical form was introduced
+			contact.Canonical = contact.Value
+		}
+		user.Contacts = append(user.Contacts, model.Contact{
+			Type:       model.ContactType(contact.Type),
+			Value:      contact.Value,
+			Canonical:  contact.Canonical,
+			Primary:    contact.Primary,
+			VerifiedAt: contact.VerifiedAt,
+		})
+	}
+	for _, attribute := range state.Attributes {
+		user.Attributes = append(user.Attributes, model.Attribute{
+			Key:   attribute.Key,
+			Type:  model.AttributeType(attribute.Type),
+			Value: attribute.Value,
+		})
+	}
+	for _, preference := range state.NotificationPreferences {
+		user.NotificationPreferences = append(user.NotificationPreferences, model.NotificationPreference{
+			Channel:  model.ContactType(preference.Channel),
+			Category: preference.Category,
+			Enabled:  preference.Enabled,
+		})
+	}
+	for _, identity := range state.Identities {
+		user.Identities = append(user.Identities, model.Identity{
+			Issuer:   identity.Issuer,
+			Subject:  identity.Subject,
+			LinkedAt: identity.LinkedAt,
+		})
+	}
+	if user.TenantID == "" {
+		user.TenantID = model.DefaultTenantID
+	}
+	return user
+}
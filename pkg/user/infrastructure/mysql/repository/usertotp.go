@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"userservice/pkg/user/domain/model"
+)
+
+func (u *userRepository) storeTOTP(userID uuid.UUID, factor *model.TOTPFactor) error {
+	_, err := u.client.ExecContext(u.ctx, `DELETE FROM user_totp_recovery_code WHERE user_id = ?`, userID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if factor == nil {
+		_, err = u.client.ExecContext(u.ctx, `DELETE FROM user_totp WHERE user_id = ?`, userID)
+		return errors.WithStack(err)
+	}
+
+	_, err = u.client.ExecContext(u.ctx,
+		`
+	INSERT INTO user_totp (user_id, encrypted_secret, confirmed_at, last_used_step, failed_attempts, locked_until, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		encrypted_secret=VALUES(encrypted_secret),
+	    confirmed_at=VALUES(confirmed_at),
+	    last_used_step=VALUES(last_used_step),
+	    failed_attempts=VALUES(failed_attempts),
+	    locked_until=VALUES(locked_until),
+	    created_at=VALUES(created_at)
+	`,
+		userID,
+		factor.EncryptedSecret,
+		toSQLNull(factor.ConfirmedAt),
+		factor.LastUsedStep,
+		factor.FailedAttempts,
+		toSQLNull(factor.LockedUntil),
+		factor.CreatedAt,
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(factor.RecoveryCodeHashes) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(factor.RecoveryCodeHashes))
+	args := make([]interface{}, 0, len(factor.RecoveryCodeHashes)*2)
+	for _, codeHash := range factor.RecoveryCodeHashes {
+		placeholders = append(placeholders, "(?, ?)")
+		args = append(args, userID, codeHash)
+	}
+	_, err = u.client.ExecContext(u.ctx,
+		`INSERT INTO user_totp_recovery_code (user_id, code_hash) VALUES `+strings.Join(placeholders, ", "),
+		args...,
+	)
+	return errors.WithStack(err)
+}
+
+func (u *userRepository) findTOTP(userID uuid.UUID) (*model.TOTPFactor, error) {
+	factor := struct {
+		EncryptedSecret []byte              `db:"encrypted_secret"`
+		ConfirmedAt     sql.Null[time.Time] `db:"confirmed_at"`
+		LastUsedStep    int64               `db:"last_used_step"`
+		FailedAttempts  int                 `db:"failed_attempts"`
+		LockedUntil     sql.Null[time.Time] `db:"locked_until"`
+		CreatedAt       time.Time           `db:"created_at"`
+	}{}
+	err := u.client.GetContext(
+		u.ctx,
+		&factor,
+		`SELECT encrypted_secret, confirmed_at, last_used_step, failed_attempts, locked_until, created_at FROM user_totp WHERE user_id = ?`,
+		userID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	var codeHashes []string
+	err = u.client.SelectContext(
+		u.ctx,
+		&codeHashes,
+		`SELECT code_hash FROM user_totp_recovery_code WHERE user_id = ? ORDER BY code_hash`,
+		userID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &model.TOTPFactor{
+		EncryptedSecret:    factor.EncryptedSecret,
+		ConfirmedAt:        fromSQLNull(factor.ConfirmedAt),
+		LastUsedStep:       factor.LastUsedStep,
+		RecoveryCodeHashes: codeHashes,
+		FailedAttempts:     factor.FailedAttempts,
+		LockedUntil:        fromSQLNull(factor.LockedUntil),
+		CreatedAt:          factor.CreatedAt,
+	}, nil
+}
@@ -14,10 +14,30 @@ func NewRepositoryProvider(client mysql.ClientContext) service.RepositoryProvide
 	return &repositoryProvider{client: client}
 }
 
+// NewEventSourcedRepositoryProvider provides user repository storing users as event streams
+func NewEventSourcedRepositoryProvider(client mysql.ClientContext) service.RepositoryProvider {
+	return &repositoryProvider{
+		client:       client,
+		eventSourced: true,
+	}
+}
+
 type repositoryProvider struct {
-	client mysql.ClientContext
+	client       mysql.ClientContext
+	eventSourced bool
 }
 
 func (r *repositoryProvider) UserRepository(ctx context.Context) model.UserRepository {
+	if r.eventSourced {
+		return repository.NewEventSourcedUserRepository(ctx, r.client)
+	}
 	return repository.NewUserRepository(ctx, r.client)
 }
+
+func (r *repositoryProvider) RoleRepository(ctx context.Context) model.RoleRepository {
+	return repository.NewRoleRepository(ctx, r.client)
+}
+
+func (r *repositoryProvider) OrganizationRepository(ctx context.Context) model.OrganizationRepository {
+	return repository.NewOrganizationRepository(ctx, r.client)
+}
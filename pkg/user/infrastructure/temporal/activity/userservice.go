@@ -2,25 +2,90 @@ package activity
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	sdkactivity "go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
 
 	appmodel "userservice/pkg/user/application/model"
 	"userservice/pkg/user/application/service"
+	"userservice/pkg/user/domain/model"
 )
 
-func NewUserServiceActivities(userService service.UserService) *UserServiceActivities {
-	return &UserServiceActivities{userService: userService}
+// Types of application errors returned by activities for expected domain errors,
+// workflows receive errors as temporal.ApplicationError and match them by type
+const (
+	ErrTypeUserNotFound                = "UserNotFound"
+	ErrTypeInvalidUserStatusTransition = "InvalidUserStatusTransition"
+	ErrTypeUserStatusOverridden        = "UserStatusOverridden"
+)
+
+var domainErrorTypes = []struct {
+	err     error
+	errType string
+}{
+	{err: model.ErrUserNotFound, errType: ErrTypeUserNotFound},
+	{err: model.ErrInvalidUserStatusTransition, errType: ErrTypeInvalidUserStatusTransition},
+	{err: model.ErrUserStatusOverridden, errType: ErrTypeUserStatusOverridden},
+}
+
+func NewUserServiceActivities(userService service.UserService, activationPolicy model.ActivationPolicy) *UserServiceActivities {
+	return &UserServiceActivities{
+		userService:      userService,
+		activationPolicy: activationPolicy,
+	}
 }
 
 type UserServiceActivities struct {
-	userService service.UserService
+	userService      service.UserService
+	activationPolicy model.ActivationPolicy
 }
 
 func (a *UserServiceActivities) FindUser(ctx context.Context, userID uuid.UUID) (appmodel.User, error) {
-	return a.userService.FindUser(ctx, userID)
+	user, err := a.userService.FindUser(ctx, userID)
+	return user, toApplicationError(err)
+}
+
+// ComputeUserStatus evaluates configured activation policy, it keeps workflow independent of policy configuration
+func (a *UserServiceActivities) ComputeUserStatus(_ context.Context, user appmodel.User) (int, error) {
+	contacts := make([]model.Contact, 0, len(user.Contacts))
+	for _, contact := range user.Contacts {
+		contacts = append(contacts, model.Contact{
+			Type:       model.ContactType(contact.Type),
+			Value:      contact.Value,
+			Primary:    contact.Primary,
+			VerifiedAt: contact.VerifiedAt,
+		})
+	}
+	return int(a.activationPolicy.UserStatus(contacts)), nil
 }
 
 func (a *UserServiceActivities) SetUserStatus(ctx context.Context, userID uuid.UUID, status int) error {
-	return a.userService.SetUserStatus(ctx, userID, status)
+	return toApplicationError(a.userService.SetSystemUserStatus(withWorkflowActor(ctx), userID, status))
+}
+
+func (a *UserServiceActivities) ExpireUserStatus(ctx context.Context, userID uuid.UUID, expiresAt time.Time) error {
+	return toApplicationError(a.userService.ExpireUserStatus(withWorkflowActor(ctx), userID, expiresAt))
+}
+
+// withWorkflowActor records workflow that scheduled activity as actor of changes
+func withWorkflowActor(ctx context.Context) context.Context {
+	info := sdkactivity.GetInfo(ctx)
+	return service.WithActor(ctx, service.Actor{
+		ID:            info.WorkflowExecution.ID,
+		Source:        service.ActorSourceWorkflow,
+		CorrelationID: info.WorkflowExecution.RunID,
+	})
+}
+
+// toApplicationError makes expected domain errors non-retryable, other errors are retried by temporal
+func toApplicationError(err error) error {
+	for _, domainError := range domainErrorTypes {
+		if errors.Is(err, domainError.err) {
+			return temporal.NewNonRetryableApplicationError(err.Error(), domainError.errType, err)
+		}
+	}
+	return err
 }
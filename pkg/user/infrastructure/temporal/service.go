@@ -2,7 +2,12 @@ package temporal
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/google/uuid"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
 	"go.temporal.io/sdk/client"
 
 	"userservice/pkg/user/domain/model"
@@ -11,8 +16,14 @@ import (
 
 const TaskQueue = "userservice_task_queue"
 
+const userStatusExpiryWorkflowIDPrefix = "user_status_expiry_"
+
 type WorkflowService interface {
+	RunUserCreatedWorkflow(ctx context.Context, id string, userID uuid.UUID) error
 	RunUserUpdatedWorkflow(ctx context.Context, id string, event model.UserUpdated) error
+	// RunUserStatusExpiryWorkflow starts timer restoring user status, previous timer of user is replaced
+	RunUserStatusExpiryWorkflow(ctx context.Context, userID uuid.UUID, expiresAt time.Time) error
+	CancelUserStatusExpiryWorkflow(ctx context.Context, userID uuid.UUID) error
 }
 
 func NewWorkflowService(temporalClient client.Client) WorkflowService {
@@ -25,6 +36,18 @@ type workflowService struct {
 	temporalClient client.Client
 }
 
+func (s *workflowService) RunUserCreatedWorkflow(ctx context.Context, id string, userID uuid.UUID) error {
+	_, err := s.temporalClient.ExecuteWorkflow(
+		ctx,
+		client.StartWorkflowOptions{
+			ID:        id,
+			TaskQueue: TaskQueue,
+		},
+		workflows.UserCreatedWorkflow, userID,
+	)
+	return err
+}
+
 func (s *workflowService) RunUserUpdatedWorkflow(ctx context.Context, id string, event model.UserUpdated) error {
 	_, err := s.temporalClient.ExecuteWorkflow(
 		ctx,
@@ -36,3 +59,25 @@ func (s *workflowService) RunUserUpdatedWorkflow(ctx context.Context, id string,
 	)
 	return err
 }
+
+func (s *workflowService) RunUserStatusExpiryWorkflow(ctx context.Context, userID uuid.UUID, expiresAt time.Time) error {
+	_, err := s.temporalClient.ExecuteWorkflow(
+		ctx,
+		client.StartWorkflowOptions{
+			ID:                       userStatusExpiryWorkflowIDPrefix + userID.String(),
+			TaskQueue:                TaskQueue,
+			WorkflowIDConflictPolicy: enumspb.WORKFLOW_ID_CONFLICT_POLICY_TERMINATE_EXISTING,
+		},
+		workflows.UserStatusExpiryWorkflow, userID, expiresAt,
+	)
+	return err
+}
+
+func (s *workflowService) CancelUserStatusExpiryWorkflow(ctx context.Context, userID uuid.UUID) error {
+	err := s.temporalClient.CancelWorkflow(ctx, userStatusExpiryWorkflowIDPrefix+userID.String(), "")
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		return nil
+	}
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"go.temporal.io/sdk/worker"
 
 	"userservice/pkg/user/application/service"
+	"userservice/pkg/user/domain/model"
 	"userservice/pkg/user/infrastructure/temporal"
 	"userservice/pkg/user/infrastructure/temporal/activity"
 	"userservice/pkg/user/infrastructure/temporal/workflows"
@@ -17,9 +18,12 @@ func InterruptChannel() <-chan interface{} {
 func NewWorker(
 	temporalClient client.Client,
 	userService service.UserService,
+	activationPolicy model.ActivationPolicy,
 ) worker.Worker {
 	w := worker.New(temporalClient, temporal.TaskQueue, worker.Options{})
-	w.RegisterActivity(activity.NewUserServiceActivities(userService))
+	w.RegisterActivity(activity.NewUserServiceActivities(userService, activationPolicy))
+	w.RegisterWorkflow(workflows.UserCreatedWorkflow)
 	w.RegisterWorkflow(workflows.UserUpdatedWorkflow)
+	w.RegisterWorkflow(workflows.UserStatusExpiryWorkflow)
 	return w
 }
@@ -0,0 +1,11 @@
+package workflows
+
+import (
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/workflow"
+)
+
+// UserCreatedWorkflow applies activation policy to new user, policy may activate user without contacts
+func UserCreatedWorkflow(ctx workflow.Context, userID uuid.UUID) error {
+	return updateUserStatus(ctx, userID)
+}
@@ -0,0 +1,33 @@
+package workflows
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/workflow"
+
+	"userservice/pkg/user/infrastructure/temporal/activity"
+)
+
+// UserStatusExpiryWorkflow waits until temporary status expires and restores previous status,
+// workflow is cancelled when status is changed before expiration
+func UserStatusExpiryWorkflow(ctx workflow.Context, userID uuid.UUID, expiresAt time.Time) error {
+	err := workflow.Sleep(ctx, expiresAt.Sub(workflow.Now(ctx)))
+	if err != nil {
+		return err
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+	})
+
+	err = workflow.ExecuteActivity(ctx, userServiceActivities.ExpireUserStatus, userID, expiresAt).Get(ctx, nil)
+	if err != nil {
+		if hasErrorType(err, activity.ErrTypeUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
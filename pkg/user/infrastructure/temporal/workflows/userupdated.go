@@ -2,8 +2,11 @@ package workflows
 
 import (
 	"errors"
+	"slices"
 	"time"
 
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 
 	appmodel "userservice/pkg/user/application/model"
@@ -13,35 +16,63 @@ import (
 
 var userServiceActivities *activity.UserServiceActivities
 
+// activationPolicyChangeID marks computation of status by activation policy activity
+const activationPolicyChangeID = "activation-policy"
+
 func UserUpdatedWorkflow(ctx workflow.Context, event model.UserUpdated) error {
-	contactInfoChanged := (event.UpdatedFields != nil && (event.UpdatedFields.Telegram != nil || event.UpdatedFields.Email != nil)) ||
-		(event.RemovedFields != nil && (event.RemovedFields.Telegram != nil || event.RemovedFields.Email != nil))
+	contactInfoChanged := (event.UpdatedFields != nil && len(event.UpdatedFields.Contacts) > 0) ||
+		(event.RemovedFields != nil && len(event.RemovedFields.Contacts) > 0)
+	// status returned under control of the system is recomputed at once
+	statusReleased := event.UpdatedFields != nil && event.UpdatedFields.StatusSource != nil &&
+		*event.UpdatedFields.StatusSource == model.StatusSourceSystem
 
-	if !contactInfoChanged {
+	if !contactInfoChanged && !statusReleased {
 		return nil
 	}
 
+	return updateUserStatus(ctx, event.UserID)
+}
+
+// updateUserStatus sets status computed by activation policy unless status is set manually
+func updateUserStatus(ctx workflow.Context, userID uuid.UUID) error {
 	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
 		StartToCloseTimeout: time.Minute,
 	})
 
 	var user appmodel.User
-	err := workflow.ExecuteActivity(ctx, userServiceActivities.FindUser, event.UserID).Get(ctx, &user)
+	err := workflow.ExecuteActivity(ctx, userServiceActivities.FindUser, userID).Get(ctx, &user)
 	if err != nil {
-		if errors.Is(err, model.ErrUserNotFound) {
+		if hasErrorType(err, activity.ErrTypeUserNotFound) {
 			return nil
 		}
 		return err
 	}
+	if model.StatusSource(user.StatusSource) == model.StatusSourceManual {
+		return nil
+	}
 
-	status := model.Blocked
-	if user.Email != nil || user.Telegram != nil {
-		status = model.Active
+	// workflows started before activation policy replay the rule they were started with
+	var status int
+	version := workflow.GetVersion(ctx, activationPolicyChangeID, workflow.DefaultVersion, 1)
+	if version == workflow.DefaultVersion {
+		status = int(model.Blocked)
+		if len(user.Contacts) > 0 {
+			status = int(model.Active)
+		}
+	} else {
+		err = workflow.ExecuteActivity(ctx, userServiceActivities.ComputeUserStatus, user).Get(ctx, &status)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = workflow.ExecuteActivity(ctx, userServiceActivities.SetUserStatus, event.UserID, int(status)).Get(ctx, nil)
+	err = workflow.ExecuteActivity(ctx, userServiceActivities.SetUserStatus, userID, status).Get(ctx, nil)
 	if err != nil {
-		if errors.Is(err, model.ErrUserNotFound) {
+		if hasErrorType(err,
+			activity.ErrTypeUserNotFound,
+			activity.ErrTypeInvalidUserStatusTransition,
+			activity.ErrTypeUserStatusOverridden,
+		) {
 			return nil
 		}
 		return err
@@ -49,3 +80,9 @@ func UserUpdatedWorkflow(ctx workflow.Context, event model.UserUpdated) error {
 
 	return nil
 }
+
+// hasErrorType reports whether activity failed with application error of one of types
+func hasErrorType(err error, errTypes ...string) bool {
+	var applicationErr *temporal.ApplicationError
+	return errors.As(err, &applicationErr) && slices.Contains(errTypes, applicationErr.Type())
+}
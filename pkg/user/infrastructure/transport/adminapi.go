@@ -0,0 +1,260 @@
+package transport
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"userservice/api/server/useradminapi"
+	"userservice/pkg/user/application/query"
+	"userservice/pkg/user/application/service"
+)
+
+const (
+	defaultExportLimit = 100
+	maxExportLimit     = 1000
+)
+
+func NewUserAdminAPI(
+	userQueryService query.UserQueryService,
+	userService service.UserService,
+	roleService service.RoleService,
+) useradminapi.UserAdminAPIServer {
+	return &userAdminAPI{
+		userQueryService: userQueryService,
+		userService:      userService,
+		roleService:      roleService,
+	}
+}
+
+type userAdminAPI struct {
+	userQueryService query.UserQueryService
+	userService      service.UserService
+	roleService      service.RoleService
+
+	useradminapi.UnimplementedUserAdminAPIServer
+}
+
+func (u userAdminAPI) SetUserStatus(ctx context.Context, request *useradminapi.SetUserStatusRequest) (*useradminapi.SetUserStatusResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	var expiresAt *time.Time
+	if request.ExpiresAt != nil {
+		t := time.Unix(*request.ExpiresAt, 0)
+		expiresAt = &t
+	}
+	err = u.userService.SetUserStatus(ctx, userID, int(request.Status), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &useradminapi.SetUserStatusResponse{}, nil
+}
+
+func (u userAdminAPI) ClearUserStatusOverride(ctx context.Context, request *useradminapi.ClearUserStatusOverrideRequest) (*useradminapi.ClearUserStatusOverrideResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	err = u.userService.ClearUserStatusOverride(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &useradminapi.ClearUserStatusOverrideResponse{}, nil
+}
+
+func (u userAdminAPI) DeleteUser(ctx context.Context, request *useradminapi.DeleteUserRequest) (*useradminapi.DeleteUserResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	err = u.userService.DeleteUser(ctx, userID, request.Hard)
+	if err != nil {
+		return nil, err
+	}
+	return &useradminapi.DeleteUserResponse{}, nil
+}
+
+func (u userAdminAPI) RestoreUser(ctx context.Context, request *useradminapi.RestoreUserRequest) (*useradminapi.RestoreUserResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	err = u.userService.RestoreUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &useradminapi.RestoreUserResponse{}, nil
+}
+
+func (u userAdminAPI) MergeUsers(ctx context.Context, request *useradminapi.MergeUsersRequest) (*useradminapi.MergeUsersResponse, error) {
+	sourceID, err := uuid.Parse(request.SourceUserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.SourceUserID)
+	}
+	targetID, err := uuid.Parse(request.TargetUserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.TargetUserID)
+	}
+	err = u.userService.MergeUsers(ctx, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	return &useradminapi.MergeUsersResponse{}, nil
+}
+
+func (u userAdminAPI) ExportUsers(ctx context.Context, request *useradminapi.ExportUsersRequest) (*useradminapi.ExportUsersResponse, error) {
+	spec := query.ListSpec{
+		Limit:      int(request.Limit),
+		Labels:     request.Labels,
+		Attributes: request.Attributes,
+	}
+	if request.AfterUserID != "" {
+		afterUserID, err := uuid.Parse(request.AfterUserID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.AfterUserID)
+		}
+		spec.AfterUserID = &afterUserID
+	}
+	if request.OrganizationID != "" {
+		organizationID, err := uuid.Parse(request.OrganizationID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.OrganizationID)
+		}
+		spec.OrganizationID = &organizationID
+	}
+	if spec.Limit <= 0 {
+		spec.Limit = defaultExportLimit
+	}
+	if spec.Limit > maxExportLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must not exceed %d", maxExportLimit)
+	}
+
+	users, err := u.userQueryService.ListUsers(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &useradminapi.ExportUsersResponse{
+		Users: make([]*useradminapi.User, 0, len(users)),
+	}
+	for _, user := range users {
+		apiUser := &useradminapi.User{
+			UserID:          user.UserID.String(),
+			Login:           user.Login,
+			Status:          useradminapi.UserStatus(user.Status), // nolint:gosec
+			DisplayName:     user.DisplayName,
+			Locale:          user.Locale,
+			Timezone:        user.Timezone,
+			AvatarURL:       user.AvatarURL,
+			Contacts:        make([]*useradminapi.Contact, 0, len(user.Contacts)),
+			Attributes:      user.Attributes,
+			Labels:          user.Labels,
+			Roles:           user.Roles,
+			Memberships:     make([]*useradminapi.Membership, 0, len(user.Memberships)),
+			StatusExpiresAt: toUnix(user.StatusExpiresAt),
+			StatusSource:    useradminapi.StatusSource(user.StatusSource), // nolint:gosec
+			MergedInto:      toString(user.MergedInto),
+		}
+		for _, membership := range user.Memberships {
+			apiUser.Memberships = append(apiUser.Memberships, &useradminapi.Membership{
+				OrganizationID: membership.OrganizationID.String(),
+				Role:           useradminapi.MembershipRole(membership.Role), // nolint:gosec
+				DisplayName:    membership.DisplayName,
+			})
+		}
+		for _, contact := range user.Contacts {
+			apiUser.Contacts = append(apiUser.Contacts, &useradminapi.Contact{
+				Type:       useradminapi.ContactType(contact.Type), // nolint:gosec
+				Value:      contact.Value,
+				Primary:    contact.Primary,
+				VerifiedAt: toUnix(contact.VerifiedAt),
+			})
+		}
+		response.Users = append(response.Users, apiUser)
+	}
+	if len(users) == spec.Limit {
+		response.LastUserID = users[len(users)-1].UserID.String()
+	}
+	return response, nil
+}
+
+func (u userAdminAPI) GetUserHistory(ctx context.Context, request *useradminapi.GetUserHistoryRequest) (*useradminapi.GetUserHistoryResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	spec := query.HistorySpec{
+		UserID: userID,
+		Limit:  int(request.Limit),
+	}
+	if request.AfterAuditID != 0 {
+		spec.AfterAuditID = &request.AfterAuditID
+	}
+	if spec.Limit <= 0 {
+		spec.Limit = defaultExportLimit
+	}
+	if spec.Limit > maxExportLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must not exceed %d", maxExportLimit)
+	}
+
+	records, err := u.userQueryService.GetUserHistory(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &useradminapi.GetUserHistoryResponse{
+		Records: make([]*useradminapi.AuditRecord, 0, len(records)),
+	}
+	for _, record := range records {
+		response.Records = append(response.Records, &useradminapi.AuditRecord{
+			AuditID:       record.AuditID,
+			Actor:         record.Actor,
+			Source:        record.Source,
+			CorrelationID: record.CorrelationID,
+			OldValue:      record.OldValue,
+			NewValue:      record.NewValue,
+			CreatedAt:     record.CreatedAt.Unix(),
+		})
+	}
+	if len(records) == spec.Limit {
+		response.LastAuditID = records[len(records)-1].AuditID
+	}
+	return response, nil
+}
+
+func (u userAdminAPI) StoreRole(ctx context.Context, request *useradminapi.StoreRoleRequest) (*useradminapi.StoreRoleResponse, error) {
+	err := u.roleService.StoreRole(ctx, request.Name, request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	return &useradminapi.StoreRoleResponse{}, nil
+}
+
+func (u userAdminAPI) AssignRole(ctx context.Context, request *useradminapi.AssignRoleRequest) (*useradminapi.AssignRoleResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	err = u.userService.AssignRole(ctx, userID, request.Role)
+	if err != nil {
+		return nil, err
+	}
+	return &useradminapi.AssignRoleResponse{}, nil
+}
+
+func (u userAdminAPI) RevokeRole(ctx context.Context, request *useradminapi.RevokeRoleRequest) (*useradminapi.RevokeRoleResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	err = u.userService.RevokeRole(ctx, userID, request.Role)
+	if err != nil {
+		return nil, err
+	}
+	return &useradminapi.RevokeRoleResponse{}, nil
+}
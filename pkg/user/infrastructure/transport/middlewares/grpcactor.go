@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"userservice/pkg/user/application/service"
+)
+
+const (
+	// actorMetadataKey is set by authenticating gateway to the ID of caller
+	actorMetadataKey         = "x-actor-id"
+	correlationIDMetadataKey = "x-correlation-id"
+)
+
+// NewGRPCActorMiddleware puts actor of request into context, correlation ID is generated when caller did not pass it
+func NewGRPCActorMiddleware(source string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		actor := service.Actor{
+			Source: source,
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get(actorMetadataKey); len(values) > 0 {
+			actor.ID = values[0]
+		}
+		if values := md.Get(correlationIDMetadataKey); len(values) > 0 {
+			actor.CorrelationID = values[0]
+		} else {
+			actor.CorrelationID = uuid.NewString()
+		}
+		return handler(service.WithActor(ctx, actor), req)
+	}
+}
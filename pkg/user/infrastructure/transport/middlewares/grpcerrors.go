@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"userservice/pkg/user/domain/model"
+)
+
+var errorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{err: model.ErrUserNotFound, code: codes.NotFound},
+	{err: model.ErrUserLoginAlreadyUsed, code: codes.AlreadyExists},
+	{err: model.ErrUserContactAlreadyUsed, code: codes.AlreadyExists},
+	{err: model.ErrInvalidContact, code: codes.InvalidArgument},
+	{err: model.ErrInvalidPhone, code: codes.InvalidArgument},
+	{err: model.ErrUserContactNotFound, code: codes.NotFound},
+	{err: model.ErrUserContactAlreadyVerified, code: codes.FailedPrecondition},
+	{err: model.ErrContactVerificationNotFound, code: codes.FailedPrecondition},
+	{err: model.ErrContactVerificationExpired, code: codes.FailedPrecondition},
+	{err: model.ErrInvalidContactVerificationCode, code: codes.InvalidArgument},
+	{err: model.ErrContactVerificationResent, code: codes.ResourceExhausted},
+	{err: model.ErrInvalidDisplayName, code: codes.InvalidArgument},
+	{err: model.ErrInvalidLocale, code: codes.InvalidArgument},
+	{err: model.ErrInvalidTimezone, code: codes.InvalidArgument},
+	{err: model.ErrInvalidAvatarURL, code: codes.InvalidArgument},
+	{err: model.ErrUnknownAttribute, code: codes.InvalidArgument},
+	{err: model.ErrInvalidAttributeValue, code: codes.InvalidArgument},
+	{err: model.ErrInvalidLabel, code: codes.InvalidArgument},
+	{err: model.ErrInvalidNotificationPreference, code: codes.InvalidArgument},
+	{err: model.ErrInvalidTenant, code: codes.InvalidArgument},
+	{err: model.ErrRoleNotFound, code: codes.NotFound},
+	{err: model.ErrInvalidRole, code: codes.InvalidArgument},
+	{err: model.ErrInvalidPermission, code: codes.InvalidArgument},
+	{err: model.ErrOrganizationNotFound, code: codes.NotFound},
+	{err: model.ErrOrganizationNameAlreadyUsed, code: codes.AlreadyExists},
+	{err: model.ErrInvalidOrganizationName, code: codes.InvalidArgument},
+	{err: model.ErrInvalidMembershipRole, code: codes.InvalidArgument},
+	{err: model.ErrOrganizationMemberNotFound, code: codes.NotFound},
+	{err: model.ErrMemberDisplayNameAlreadyUsed, code: codes.AlreadyExists},
+	{err: model.ErrOrganizationWithoutOwner, code: codes.FailedPrecondition},
+	{err: model.ErrUserNotDeleted, code: codes.FailedPrecondition},
+	{err: model.ErrInvalidUserStatus, code: codes.InvalidArgument},
+	{err: model.ErrInvalidUserStatusTransition, code: codes.FailedPrecondition},
+	{err: model.ErrInvalidStatusExpiration, code: codes.InvalidArgument},
+	{err: model.ErrUserStatusOverridden, code: codes.FailedPrecondition},
+	{err: model.ErrMergeSameUser, code: codes.InvalidArgument},
+	{err: model.ErrMergeDeletedUser, code: codes.FailedPrecondition},
+	{err: model.ErrUserMerged, code: codes.FailedPrecondition},
+	{err: model.ErrInvalidPassword, code: codes.InvalidArgument},
+	{err: model.ErrInvalidCredentials, code: codes.Unauthenticated},
+	{err: model.ErrCredentialsLocked, code: codes.PermissionDenied},
+	{err: model.ErrAPITokenNotFound, code: codes.NotFound},
+	{err: model.ErrAPITokenNameAlreadyUsed, code: codes.AlreadyExists},
+	{err: model.ErrInvalidAPITokenName, code: codes.InvalidArgument},
+	{err: model.ErrInvalidAPITokenExpiration, code: codes.InvalidArgument},
+	{err: model.ErrInvalidAPIToken, code: codes.Unauthenticated},
+	{err: model.ErrTOTPAlreadyEnrolled, code: codes.FailedPrecondition},
+	{err: model.ErrTOTPNotEnrolled, code: codes.FailedPrecondition},
+	{err: model.ErrInvalidTOTPCode, code: codes.Unauthenticated},
+	{err: model.ErrTOTPNotConfigured, code: codes.Unimplemented},
+	{err: model.ErrInvalidIdentity, code: codes.InvalidArgument},
+	{err: model.ErrIdentityAlreadyLinked, code: codes.AlreadyExists},
+	{err: model.ErrIdentityNotFound, code: codes.NotFound},
+}
+
+// NewGRPCErrorsMiddleware converts domain errors into gRPC statuses, message of status is the domain error text
+func NewGRPCErrorsMiddleware() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		resp, err = handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		if _, ok := status.FromError(err); ok {
+			return resp, err
+		}
+		for _, e := range errorCodes {
+			if errors.Is(err, e.err) {
+				return resp, status.Error(e.code, e.err.Error())
+			}
+		}
+		return resp, status.Error(codes.Internal, err.Error())
+	}
+}
+
+func errorCode(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if s, ok := status.FromError(err); ok {
+		return s.Code()
+	}
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+	return codes.Internal
+}
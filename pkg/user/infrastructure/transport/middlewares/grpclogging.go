@@ -6,6 +6,9 @@ import (
 
 	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 func NewGRPCLoggingMiddleware(logger logging.Logger) grpc.UnaryServerInterceptor {
@@ -15,7 +18,7 @@ func NewGRPCLoggingMiddleware(logger logging.Logger) grpc.UnaryServerInterceptor
 		resp, err = handler(ctx, req)
 
 		fields := logging.Fields{
-			"args":     req,
+			"args":     redact(req),
 			"duration": time.Since(start).String(),
 			"method":   info.FullMethod,
 		}
@@ -29,3 +32,38 @@ func NewGRPCLoggingMiddleware(logger logging.Logger) grpc.UnaryServerInterceptor
 		return resp, err
 	}
 }
+
+// redact returns copy of request without fields marked with debug_redact option, such as passwords
+func redact(req interface{}) interface{} {
+	m, ok := req.(proto.Message)
+	if !ok {
+		return req
+	}
+	m = proto.Clone(m)
+	clearRedacted(m.ProtoReflect())
+	return m
+}
+
+func clearRedacted(m protoreflect.Message) {
+	var redacted []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if options, ok := fd.Options().(*descriptorpb.FieldOptions); ok && options.GetDebugRedact() {
+			redacted = append(redacted, fd)
+			return true
+		}
+		if fd.Message() == nil || fd.IsMap() {
+			return true
+		}
+		if fd.IsList() {
+			for i := 0; i < v.List().Len(); i++ {
+				clearRedacted(v.List().Get(i).Message())
+			}
+			return true
+		}
+		clearRedacted(v.Message())
+		return true
+	})
+	for _, fd := range redacted {
+		m.Clear(fd)
+	}
+}
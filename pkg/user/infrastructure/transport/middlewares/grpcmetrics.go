@@ -2,14 +2,10 @@ package middlewares
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-
-	"userservice/pkg/user/domain/model"
 )
 
 func NewGRPCMetricsMiddleware() grpc.UnaryServerInterceptor {
@@ -27,16 +23,7 @@ func NewGRPCMetricsMiddleware() grpc.UnaryServerInterceptor {
 
 		resp, err = handler(ctx, req)
 
-		var code codes.Code
-		switch {
-		case err == nil:
-			code = codes.OK
-		case errors.Is(err, model.ErrUserNotFound):
-			code = codes.NotFound
-		case err != nil:
-			code = codes.Internal
-		}
-
+		code := errorCode(err)
 		duration := time.Since(start).Seconds()
 
 		vec.
@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"userservice/pkg/user/application/service"
+	"userservice/pkg/user/domain/model"
+)
+
+// tenantMetadataKey is set by product calling the service, logins, contacts and identities are unique within tenant
+const tenantMetadataKey = "x-tenant-id"
+
+// NewGRPCTenantMiddleware puts tenant of request into context, requests without tenant belong to default tenant
+func NewGRPCTenantMiddleware() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		tenantID := model.DefaultTenantID
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get(tenantMetadataKey); len(values) > 0 {
+			tenantID, err = model.NewTenantID(values[0])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return handler(service.WithTenant(ctx, tenantID), req)
+	}
+}
@@ -2,6 +2,8 @@ package transport
 
 import (
 	"context"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
@@ -11,26 +13,45 @@ import (
 	appmodel "userservice/pkg/user/application/model"
 	"userservice/pkg/user/application/query"
 	"userservice/pkg/user/application/service"
+	"userservice/pkg/user/domain/model"
 )
 
-func NewUserInternalAPI(
+func NewUserPublicAPI(
 	userQueryService query.UserQueryService,
+	organizationQueryService query.OrganizationQueryService,
 	userService service.UserService,
+	organizationService service.OrganizationService,
+	credentialsService service.CredentialsService,
+	apiTokenService service.APITokenService,
+	totpService service.TOTPService,
+	identityService service.IdentityService,
 ) userpublicapi.UserPublicAPIServer {
-	return &userInternalAPI{
-		userQueryService: userQueryService,
-		userService:      userService,
+	return &userPublicAPI{
+		userQueryService:         userQueryService,
+		organizationQueryService: organizationQueryService,
+		userService:              userService,
+		organizationService:      organizationService,
+		credentialsService:       credentialsService,
+		apiTokenService:          apiTokenService,
+		totpService:              totpService,
+		identityService:          identityService,
 	}
 }
 
-type userInternalAPI struct {
-	userQueryService query.UserQueryService
-	userService      service.UserService
+type userPublicAPI struct {
+	userQueryService         query.UserQueryService
+	organizationQueryService query.OrganizationQueryService
+	userService              service.UserService
+	organizationService      service.OrganizationService
+	credentialsService       service.CredentialsService
+	apiTokenService          service.APITokenService
+	totpService              service.TOTPService
+	identityService          service.IdentityService
 
 	userpublicapi.UnimplementedUserPublicAPIServer
 }
 
-func (u userInternalAPI) StoreUser(ctx context.Context, request *userpublicapi.StoreUserRequest) (*userpublicapi.StoreUserResponse, error) {
+func (u userPublicAPI) StoreUser(ctx context.Context, request *userpublicapi.StoreUserRequest) (*userpublicapi.StoreUserResponse, error) {
 	var (
 		userID uuid.UUID
 		err    error
@@ -42,11 +63,41 @@ func (u userInternalAPI) StoreUser(ctx context.Context, request *userpublicapi.S
 		}
 	}
 
+	contacts := make([]appmodel.Contact, 0, len(request.Contacts)+2)
+	for _, contact := range request.Contacts {
+		contacts = append(contacts, appmodel.Contact{
+			Type:    int(contact.Type),
+			Value:   contact.Value,
+			Primary: contact.Primary,
+		})
+	}
+	for _, legacy := range []struct {
+		contactType userpublicapi.ContactType
+		value       *string
+	}{
+		{contactType: userpublicapi.ContactType_Email, value: request.Email},
+		{contactType: userpublicapi.ContactType_Telegram, value: request.Telegram},
+	} {
+		if legacy.value == nil {
+			continue
+		}
+		// deprecated field is stored as primary contact, so it can not be mixed with primary contact of the same type
+		if slices.ContainsFunc(request.Contacts, func(c *userpublicapi.Contact) bool {
+			return c.Type == legacy.contactType && c.Primary
+		}) {
+			return nil, status.Errorf(codes.InvalidArgument, "deprecated field and primary contact of type %s are both set", legacy.contactType)
+		}
+		contacts = append(contacts, appmodel.Contact{Type: int(legacy.contactType), Value: *legacy.value, Primary: true})
+	}
+
 	userID, err = u.userService.StoreUser(ctx, appmodel.User{
-		UserID:   userID,
-		Login:    request.Login,
-		Email:    request.Email,
-		Telegram: request.Telegram,
+		UserID:      userID,
+		Login:       request.Login,
+		DisplayName: request.DisplayName,
+		Locale:      request.Locale,
+		Timezone:    request.Timezone,
+		AvatarURL:   request.AvatarURL,
+		Contacts:    contacts,
 	})
 	if err != nil {
 		return nil, err
@@ -57,7 +108,7 @@ func (u userInternalAPI) StoreUser(ctx context.Context, request *userpublicapi.S
 	}, nil
 }
 
-func (u userInternalAPI) FindUser(ctx context.Context, request *userpublicapi.FindUserRequest) (*userpublicapi.FindUserResponse, error) {
+func (u userPublicAPI) FindUser(ctx context.Context, request *userpublicapi.FindUserRequest) (*userpublicapi.FindUserResponse, error) {
 	userID, err := uuid.Parse(request.UserID)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
@@ -67,13 +118,403 @@ func (u userInternalAPI) FindUser(ctx context.Context, request *userpublicapi.Fi
 		return nil, err
 	}
 	if user == nil {
-		return nil, status.Errorf(codes.NotFound, "user %q not found", request.UserID)
-	}
-	return &userpublicapi.FindUserResponse{
-		UserID:   userID.String(),
-		Status:   userpublicapi.UserStatus(user.Status), // nolint:gosec
-		Login:    user.Login,
-		Email:    user.Email,
-		Telegram: user.Telegram,
+		// domain error is translated by errors middleware, so client recognises it by message
+		return nil, model.ErrUserNotFound
+	}
+	return toFindUserResponse(*user), nil
+}
+
+func (u userPublicAPI) FindUserByIdentity(ctx context.Context, request *userpublicapi.FindUserByIdentityRequest) (*userpublicapi.FindUserResponse, error) {
+	user, err := u.userQueryService.FindUserByIdentity(ctx, request.Issuer, request.Subject)
+	if err != nil {
+		return nil, err
+	}
+	return toFindUserResponse(*user), nil
+}
+
+func (u userPublicAPI) VerifyContact(ctx context.Context, request *userpublicapi.VerifyContactRequest) (*userpublicapi.VerifyContactResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	err = u.userService.VerifyContact(ctx, userID, int(request.Type), request.Value, request.Code)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.VerifyContactResponse{}, nil
+}
+
+func (u userPublicAPI) ResendVerification(ctx context.Context, request *userpublicapi.ResendVerificationRequest) (*userpublicapi.ResendVerificationResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	err = u.userService.ResendContactVerification(ctx, userID, int(request.Type), request.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.ResendVerificationResponse{}, nil
+}
+
+func (u userPublicAPI) SetUserAttributes(ctx context.Context, request *userpublicapi.SetUserAttributesRequest) (*userpublicapi.SetUserAttributesResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	err = u.userService.SetUserAttributes(ctx, userID, request.Attributes, request.Labels)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.SetUserAttributesResponse{}, nil
+}
+
+func (u userPublicAPI) SetNotificationPreferences(ctx context.Context, request *userpublicapi.SetNotificationPreferencesRequest) (*userpublicapi.SetNotificationPreferencesResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	preferences := make([]appmodel.NotificationPreference, 0, len(request.Preferences))
+	for _, preference := range request.Preferences {
+		preferences = append(preferences, appmodel.NotificationPreference{
+			Channel:  int(preference.Channel),
+			Category: preference.Category,
+			Enabled:  preference.Enabled,
+		})
+	}
+	err = u.userService.SetNotificationPreferences(ctx, userID, preferences)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.SetNotificationPreferencesResponse{}, nil
+}
+
+func (u userPublicAPI) CheckPermission(ctx context.Context, request *userpublicapi.CheckPermissionRequest) (*userpublicapi.CheckPermissionResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	granted, err := u.userQueryService.CheckPermission(ctx, userID, request.Permission)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.CheckPermissionResponse{
+		Granted: granted,
+	}, nil
+}
+
+func (u userPublicAPI) SetPassword(ctx context.Context, request *userpublicapi.SetPasswordRequest) (*userpublicapi.SetPasswordResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	err = u.credentialsService.SetPassword(ctx, userID, request.Password)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.SetPasswordResponse{}, nil
+}
+
+func (u userPublicAPI) VerifyPassword(ctx context.Context, request *userpublicapi.VerifyPasswordRequest) (*userpublicapi.VerifyPasswordResponse, error) {
+	userID, err := u.credentialsService.VerifyPassword(ctx, request.Login, request.Password)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.VerifyPasswordResponse{
+		UserID: userID.String(),
+	}, nil
+}
+
+func (u userPublicAPI) CreateAPIToken(ctx context.Context, request *userpublicapi.CreateAPITokenRequest) (*userpublicapi.CreateAPITokenResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	var expiresAt *time.Time
+	if request.ExpiresAt != nil {
+		t := time.Unix(*request.ExpiresAt, 0)
+		expiresAt = &t
+	}
+	tokenID, token, err := u.apiTokenService.CreateAPIToken(ctx, userID, request.Name, request.Scopes, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.CreateAPITokenResponse{
+		TokenID: tokenID.String(),
+		Token:   token,
+	}, nil
+}
+
+func (u userPublicAPI) ListAPITokens(ctx context.Context, request *userpublicapi.ListAPITokensRequest) (*userpublicapi.ListAPITokensResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	tokens, err := u.userQueryService.ListAPITokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	response := &userpublicapi.ListAPITokensResponse{
+		Tokens: make([]*userpublicapi.APIToken, 0, len(tokens)),
+	}
+	for _, token := range tokens {
+		response.Tokens = append(response.Tokens, &userpublicapi.APIToken{
+			TokenID:    token.TokenID.String(),
+			Name:       token.Name,
+			Scopes:     token.Scopes,
+			ExpiresAt:  toUnix(token.ExpiresAt),
+			LastUsedAt: toUnix(token.LastUsedAt),
+			CreatedAt:  token.CreatedAt.Unix(),
+		})
+	}
+	return response, nil
+}
+
+func (u userPublicAPI) RevokeAPIToken(ctx context.Context, request *userpublicapi.RevokeAPITokenRequest) (*userpublicapi.RevokeAPITokenResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	tokenID, err := uuid.Parse(request.TokenID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.TokenID)
+	}
+	err = u.apiTokenService.RevokeAPIToken(ctx, userID, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.RevokeAPITokenResponse{}, nil
+}
+
+func (u userPublicAPI) ValidateToken(ctx context.Context, request *userpublicapi.ValidateTokenRequest) (*userpublicapi.ValidateTokenResponse, error) {
+	userID, scopes, err := u.apiTokenService.ValidateAPIToken(ctx, request.Token)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.ValidateTokenResponse{
+		UserID: userID.String(),
+		Scopes: scopes,
+	}, nil
+}
+
+func (u userPublicAPI) EnrollTOTP(ctx context.Context, request *userpublicapi.EnrollTOTPRequest) (*userpublicapi.EnrollTOTPResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	secret, uri, err := u.totpService.EnrollTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.EnrollTOTPResponse{
+		Secret: secret,
+		Uri:    uri,
+	}, nil
+}
+
+func (u userPublicAPI) ConfirmTOTP(ctx context.Context, request *userpublicapi.ConfirmTOTPRequest) (*userpublicapi.ConfirmTOTPResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	recoveryCodes, err := u.totpService.ConfirmTOTP(ctx, userID, request.Code)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.ConfirmTOTPResponse{
+		RecoveryCodes: recoveryCodes,
 	}, nil
 }
+
+func (u userPublicAPI) VerifyTOTP(ctx context.Context, request *userpublicapi.VerifyTOTPRequest) (*userpublicapi.VerifyTOTPResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	err = u.totpService.VerifyTOTP(ctx, userID, request.Code)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.VerifyTOTPResponse{}, nil
+}
+
+func (u userPublicAPI) RemoveTOTP(ctx context.Context, request *userpublicapi.RemoveTOTPRequest) (*userpublicapi.RemoveTOTPResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	err = u.totpService.RemoveTOTP(ctx, userID, request.Code)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.RemoveTOTPResponse{}, nil
+}
+
+func (u userPublicAPI) LinkIdentity(ctx context.Context, request *userpublicapi.LinkIdentityRequest) (*userpublicapi.LinkIdentityResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	err = u.identityService.LinkIdentity(ctx, userID, request.Issuer, request.Subject)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.LinkIdentityResponse{}, nil
+}
+
+func (u userPublicAPI) UnlinkIdentity(ctx context.Context, request *userpublicapi.UnlinkIdentityRequest) (*userpublicapi.UnlinkIdentityResponse, error) {
+	userID, err := uuid.Parse(request.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.UserID)
+	}
+	err = u.identityService.UnlinkIdentity(ctx, userID, request.Issuer, request.Subject)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.UnlinkIdentityResponse{}, nil
+}
+
+func (u userPublicAPI) CreateOrganization(ctx context.Context, request *userpublicapi.CreateOrganizationRequest) (*userpublicapi.CreateOrganizationResponse, error) {
+	ownerID, err := uuid.Parse(request.OwnerID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.OwnerID)
+	}
+	organizationID, err := u.organizationService.CreateOrganization(ctx, request.Name, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.CreateOrganizationResponse{
+		OrganizationID: organizationID.String(),
+	}, nil
+}
+
+func (u userPublicAPI) FindOrganization(ctx context.Context, request *userpublicapi.FindOrganizationRequest) (*userpublicapi.FindOrganizationResponse, error) {
+	organizationID, err := uuid.Parse(request.OrganizationID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", request.OrganizationID)
+	}
+	organization, err := u.organizationQueryService.FindOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	response := &userpublicapi.FindOrganizationResponse{
+		OrganizationID: organization.OrganizationID.String(),
+		Name:           organization.Name,
+		Members:        make([]*userpublicapi.OrganizationMember, 0, len(organization.Members)),
+	}
+	for _, member := range organization.Members {
+		response.Members = append(response.Members, &userpublicapi.OrganizationMember{
+			UserID:      member.UserID.String(),
+			Role:        userpublicapi.MembershipRole(member.Role), // nolint:gosec
+			DisplayName: member.DisplayName,
+			JoinedAt:    member.JoinedAt.Unix(),
+		})
+	}
+	return response, nil
+}
+
+func (u userPublicAPI) StoreMember(ctx context.Context, request *userpublicapi.StoreMemberRequest) (*userpublicapi.StoreMemberResponse, error) {
+	organizationID, userID, err := parseMemberIDs(request.OrganizationID, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+	err = u.organizationService.StoreMember(ctx, organizationID, userID, int(request.Role), request.DisplayName)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.StoreMemberResponse{}, nil
+}
+
+func (u userPublicAPI) RemoveMember(ctx context.Context, request *userpublicapi.RemoveMemberRequest) (*userpublicapi.RemoveMemberResponse, error) {
+	organizationID, userID, err := parseMemberIDs(request.OrganizationID, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+	err = u.organizationService.RemoveMember(ctx, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &userpublicapi.RemoveMemberResponse{}, nil
+}
+
+func toFindUserResponse(user appmodel.User) *userpublicapi.FindUserResponse {
+	response := &userpublicapi.FindUserResponse{
+		UserID:                  user.UserID.String(),
+		Status:                  userpublicapi.UserStatus(user.Status), // nolint:gosec
+		Login:                   user.Login,
+		DisplayName:             user.DisplayName,
+		Locale:                  user.Locale,
+		Timezone:                user.Timezone,
+		AvatarURL:               user.AvatarURL,
+		Contacts:                make([]*userpublicapi.Contact, 0, len(user.Contacts)),
+		Attributes:              user.Attributes,
+		Labels:                  user.Labels,
+		Roles:                   user.Roles,
+		Memberships:             make([]*userpublicapi.Membership, 0, len(user.Memberships)),
+		StatusExpiresAt:         toUnix(user.StatusExpiresAt),
+		StatusSource:            userpublicapi.StatusSource(user.StatusSource), // nolint:gosec
+		MergedInto:              toString(user.MergedInto),
+		NotificationPreferences: make([]*userpublicapi.NotificationPreference, 0, len(user.NotificationPreferences)),
+	}
+	for _, preference := range user.NotificationPreferences {
+		response.NotificationPreferences = append(response.NotificationPreferences, &userpublicapi.NotificationPreference{
+			Channel:  userpublicapi.ContactType(preference.Channel), // nolint:gosec
+			Category: preference.Category,
+			Enabled:  preference.Enabled,
+		})
+	}
+	for _, membership := range user.Memberships {
+		response.Memberships = append(response.Memberships, &userpublicapi.Membership{
+			OrganizationID: membership.OrganizationID.String(),
+			Role:           userpublicapi.MembershipRole(membership.Role), // nolint:gosec
+			DisplayName:    membership.DisplayName,
+		})
+	}
+	for _, contact := range user.Contacts {
+		contactType := userpublicapi.ContactType(contact.Type) // nolint:gosec
+		response.Contacts = append(response.Contacts, &userpublicapi.Contact{
+			Type:       contactType,
+			Value:      contact.Value,
+			Primary:    contact.Primary,
+			VerifiedAt: toUnix(contact.VerifiedAt),
+		})
+		if !contact.Primary {
+			continue
+		}
+		switch contactType {
+		case userpublicapi.ContactType_Email:
+			response.Email = &contact.Value
+		case userpublicapi.ContactType_Telegram:
+			response.Telegram = &contact.Value
+		default:
+		}
+	}
+	return response
+}
+
+func parseMemberIDs(organizationID, userID string) (uuid.UUID, uuid.UUID, error) {
+	oID, err := uuid.Parse(organizationID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", organizationID)
+	}
+	uID, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid uuid %q", userID)
+	}
+	return oID, uID, nil
+}
+
+func toUnix(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+	unix := t.Unix()
+	return &unix
+}
+
+func toString(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
+}